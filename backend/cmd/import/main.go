@@ -0,0 +1,95 @@
+// Command import loads items from a CSV or XLSX file, the same way as
+// POST /api/v1/items/import. It validates only unless -commit is given.
+//
+//	go run ./cmd/import -file aset.xlsx -mapping '{"serial_number":"No. Seri"}' -user admin -commit
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"warehouse-system/internal/config"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+	"warehouse-system/internal/services"
+	"warehouse-system/pkg/database"
+	"warehouse-system/pkg/spreadsheet"
+)
+
+func main() {
+	filePath := flag.String("file", "", "CSV or XLSX file to import")
+	sheet := flag.String("sheet", "", "XLSX sheet name (defaults to the first sheet)")
+	mapping := flag.String("mapping", "", "JSON object mapping import fields to column headers")
+	username := flag.String("user", "", "username recorded as the actor of the import")
+	commit := flag.Bool("commit", false, "insert the items instead of only validating them")
+	flag.Parse()
+
+	if *filePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+	cfg := config.Load()
+
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	repos := repositories.NewRepositories(db)
+	svc := services.NewServices(repos, cfg)
+
+	opts := models.ImportOptions{DryRun: !*commit}
+	if *mapping != "" {
+		if err := json.Unmarshal([]byte(*mapping), &opts.Mapping); err != nil {
+			log.Fatal("Invalid mapping:", err)
+		}
+	}
+
+	actor := models.Actor{Name: "import-cli"}
+	if *username != "" {
+		user, err := repos.User.GetUserByUsername(*username)
+		if err != nil {
+			log.Fatal("Unknown user:", err)
+		}
+		actor = user.Actor()
+	}
+
+	format, err := spreadsheet.FormatFromFilename(*filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Open(*filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := spreadsheet.ReadAll(file, format, *sheet)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := svc.Import.ImportItems(actor, rows, opts)
+	if err != nil {
+		log.Fatal("Import failed:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetAuditLogs(c *gin.Context) {
+	var params models.AuditSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	logs, total, err := h.svc.Audit.GetAuditLogs(&params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       logs,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
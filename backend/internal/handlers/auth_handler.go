@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	tokens, err := h.svc.Auth.Login(&req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handlers) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	tokens, err := h.svc.Auth.Refresh(&req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handlers) Logout(c *gin.Context) {
+	if err := h.svc.Auth.Logout(c.GetString(contextSessionKey)); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func (h *Handlers) GetCurrentUser(c *gin.Context) {
+	c.JSON(http.StatusOK, currentUser(c))
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetCategories(c *gin.Context) {
+	categorys, err := h.svc.Category.GetCategories()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categorys)
+}
+
+func (h *Handlers) CreateCategory(c *gin.Context) {
+	var req models.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	category, err := h.svc.Category.CreateCategory(currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *Handlers) UpdateCategory(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	category, err := h.svc.Category.UpdateCategory(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *Handlers) DeleteCategory(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	reassignTo, ok := parseDeleteParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Category.DeleteCategory(currentActor(c), id.String(), reassignTo); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+func (h *Handlers) GetDeletedCategories(c *gin.Context) {
+	rows, err := h.svc.Category.GetDeletedCategories()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
+
+func (h *Handlers) RestoreCategory(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	row, err := h.svc.Category.RestoreCategory(currentActor(c), id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, row)
+}
+
+func (h *Handlers) PurgeCategory(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Category.PurgeCategory(currentActor(c), id.String()); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category purged permanently"})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetCustodians(c *gin.Context) {
+	var params models.CustodianSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	custodians, total, err := h.svc.Custodian.GetCustodians(currentScope(c), &params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       custodians,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) GetCustodian(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	custodian, err := h.svc.Custodian.GetCustodian(currentScope(c), id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, custodian)
+}
+
+func (h *Handlers) CreateCustodian(c *gin.Context) {
+	var req models.CreateCustodianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	custodian, err := h.svc.Custodian.CreateCustodian(currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, custodian)
+}
+
+func (h *Handlers) UpdateCustodian(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateCustodianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	custodian, err := h.svc.Custodian.UpdateCustodian(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, custodian)
+}
+
+func (h *Handlers) DeleteCustodian(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	reassignTo, ok := parseDeleteParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Custodian.DeleteCustodian(currentActor(c), id.String(), reassignTo); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Custodian deleted successfully"})
+}
+
+func (h *Handlers) GetCustodianItems(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var params models.ItemSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	items, total, err := h.svc.Custodian.GetCustodianItems(currentScope(c), id.String(), &params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       items,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) GetCustodianStatementPDF(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.svc.Custodian.WriteStatementPDF(currentScope(c), id.String(), &buf); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("Pernyataan_%s.pdf", id)
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+func (h *Handlers) AssignItemCustodian(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AssignCustodianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	item, err := h.svc.Custodian.AssignCustodian(currentScope(c), currentActor(c), id, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
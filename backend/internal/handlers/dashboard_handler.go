@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetDashboardSummary(c *gin.Context) {
+	summary, err := h.svc.Dashboard.GetSummary(currentScope(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *Handlers) GetRecentTransactions(c *gin.Context) {
+	transactions, err := h.svc.Dashboard.GetRecentTransactions(currentScope(c))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetDisposals(c *gin.Context) {
+	var params models.DisposalSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	disposals, total, err := h.svc.Disposal.GetDisposals(&params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       disposals,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) CreateDisposal(c *gin.Context) {
+	var req models.CreateDisposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	disposal, err := h.svc.Disposal.CreateDisposal(currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, disposal)
+}
+
+func (h *Handlers) GetDisposal(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	disposal, err := h.svc.Disposal.GetDisposal(id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disposal)
+}
+
+func (h *Handlers) ApproveDisposal(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ApproveDisposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	disposal, err := h.svc.Disposal.ApproveDisposal(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disposal)
+}
+
+func (h *Handlers) RejectDisposal(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.RejectDisposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	disposal, err := h.svc.Disposal.RejectDisposal(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disposal)
+}
+
+func (h *Handlers) ExecuteDisposal(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ExecuteDisposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	disposal, err := h.svc.Disposal.ExecuteDisposal(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disposal)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"warehouse-system/internal/models"
+	"warehouse-system/pkg/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) ExportItems(c *gin.Context) {
+	var params models.ItemSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	w := newDownloadWriter(c, "barang", format)
+	w.finish(h.svc.Export.ExportItems(currentScope(c), &params, format, w))
+}
+
+func (h *Handlers) ExportTransactions(c *gin.Context) {
+	var params models.TransactionSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+
+	w := newDownloadWriter(c, "transaksi", format)
+	w.finish(h.svc.Export.ExportTransactions(currentScope(c), &params, format, w))
+}
+
+// exportFormat reads the "format" query parameter, defaulting to CSV
+func exportFormat(c *gin.Context) (spreadsheet.Format, bool) {
+	format, err := spreadsheet.ParseFormat(c.DefaultQuery("format", "csv"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+		return "", false
+	}
+	return format, true
+}
+
+// downloadWriter sends the attachment headers on the first write, so that
+// errors raised before any data is produced still get a JSON error response.
+// The spreadsheet writers buffer the header row and first rows, so that
+// covers a failing first query.
+type downloadWriter struct {
+	c        *gin.Context
+	filename string
+	format   spreadsheet.Format
+	started  bool
+}
+
+func newDownloadWriter(c *gin.Context, name string, format spreadsheet.Format) *downloadWriter {
+	return &downloadWriter{
+		c:        c,
+		filename: fmt.Sprintf("%s_%s.%s", name, time.Now().Format("20060102"), format),
+		format:   format,
+	}
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Content-Type", spreadsheet.ContentType(w.format))
+		w.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, w.filename))
+		w.c.Status(http.StatusOK)
+	}
+	return w.c.Writer.Write(p)
+}
+
+// finish reports an export error. Once streaming has started the status can
+// no longer change, so the connection is cut short instead.
+func (w *downloadWriter) finish(err error) {
+	if err == nil {
+		return
+	}
+	if !w.started {
+		handleServiceError(w.c, err)
+		return
+	}
+	log.Printf("export %s failed: %v", w.filename, err)
+	w.c.Abort()
+}
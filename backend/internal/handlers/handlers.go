@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/services"
+	"warehouse-system/pkg/label"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Handlers struct {
+	svc *services.Services
+}
+
+func NewHandlers(svc *services.Services) *Handlers {
+	return &Handlers{svc: svc}
+}
+
+// ErrorResponse is the JSON body returned for every failed request. A
+// deletion refused because items still depend on the record lists them in
+// BlockingItems, capped, with BlockingCount giving the full number.
+type ErrorResponse struct {
+	Error         string                `json:"error"`
+	Details       string                `json:"details,omitempty"`
+	BlockingItems []models.BlockingItem `json:"blocking_items,omitempty"`
+	BlockingCount int64                 `json:"blocking_count,omitempty"`
+}
+
+func respondError(c *gin.Context, status int, message string, err error) {
+	resp := ErrorResponse{Error: message}
+	if err != nil {
+		resp.Details = err.Error()
+	}
+	c.AbortWithStatusJSON(status, resp)
+}
+
+// handleServiceError maps errors returned by the service layer to HTTP statuses
+func handleServiceError(c *gin.Context, err error) {
+	var blocked *services.BlockedError
+	if errors.As(err, &blocked) {
+		c.AbortWithStatusJSON(http.StatusConflict, ErrorResponse{
+			Error:         err.Error(),
+			BlockingItems: blocked.Items,
+			BlockingCount: blocked.Total,
+		})
+		return
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, "Resource not found", nil)
+	case errors.Is(err, services.ErrDuplicateSerialNumber),
+		errors.Is(err, services.ErrInactiveSerialNumber),
+		errors.Is(err, services.ErrDuplicateName),
+		errors.Is(err, services.ErrNotInTrash),
+		errors.Is(err, services.ErrStillReferenced),
+		errors.Is(err, services.ErrStorageLocationInUse),
+		errors.Is(err, services.ErrWarehouseNotEmpty),
+		errors.Is(err, services.ErrDefaultWarehouse),
+		errors.Is(err, services.ErrOPDHasSubUnits),
+		errors.Is(err, services.ErrStillHasItems),
+		errors.Is(err, services.ErrDuplicateNIP),
+		errors.Is(err, services.ErrReorganizationBlocked):
+		respondError(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalidCredentials),
+		errors.Is(err, services.ErrInvalidToken):
+		respondError(c, http.StatusUnauthorized, err.Error(), nil)
+	case errors.Is(err, services.ErrForbidden),
+		errors.Is(err, services.ErrWarehouseStaffOnly),
+		errors.Is(err, services.ErrReceiverOnly),
+		errors.Is(err, services.ErrSelfReview):
+		respondError(c, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalidItemLocation),
+		errors.Is(err, services.ErrSourceOPDMismatch),
+		errors.Is(err, services.ErrSourceWarehouseMismatch),
+		errors.Is(err, services.ErrAlreadyReversed),
+		errors.Is(err, services.ErrReversalNotReversible),
+		errors.Is(err, services.ErrNotLatestTransaction),
+		errors.Is(err, services.ErrAlreadyDocumented),
+		errors.Is(err, services.ErrTransactionInTransit),
+		errors.Is(err, services.ErrTransactionBounced),
+		errors.Is(err, services.ErrNotInTransit),
+		errors.Is(err, services.ErrOpnameAlreadyOpen),
+		errors.Is(err, services.ErrOpnameClosed),
+		errors.Is(err, services.ErrOpnameNotClosed),
+		errors.Is(err, services.ErrOpnameCorrected),
+		errors.Is(err, services.ErrRequestNotPending),
+		errors.Is(err, services.ErrRequestNotApproved),
+		errors.Is(err, services.ErrItemInMaintenance),
+		errors.Is(err, services.ErrTicketClosed),
+		errors.Is(err, services.ErrNotDisposable),
+		errors.Is(err, services.ErrItemNotDeletable),
+		errors.Is(err, services.ErrItemDisposed),
+		errors.Is(err, services.ErrAlreadyProposed),
+		errors.Is(err, services.ErrDisposalNotProposed),
+		errors.Is(err, services.ErrDisposalNotApproved),
+		errors.Is(err, services.ErrAlreadyReviewed):
+		respondError(c, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalidDirection),
+		errors.Is(err, services.ErrMissingOPD),
+		errors.Is(err, services.ErrInactiveOPD),
+		errors.Is(err, services.ErrMissingWarehouse),
+		errors.Is(err, services.ErrInactiveWarehouse),
+		errors.Is(err, services.ErrInvalidOPDParent),
+		errors.Is(err, services.ErrInvalidReorganization),
+		errors.Is(err, services.ErrInvalidReassignment),
+		errors.Is(err, services.ErrInvalidCustodian):
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, services.ErrImportEmpty),
+		errors.Is(err, services.ErrImportColumnMissing),
+		errors.Is(err, services.ErrImportUnknownField):
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, services.ErrHandoverMixed),
+		errors.Is(err, services.ErrReversalHandover),
+		errors.Is(err, services.ErrHandoverEmpty),
+		errors.Is(err, services.ErrInvalidOpnameLocation),
+		errors.Is(err, services.ErrInvalidStorageLocation),
+		errors.Is(err, services.ErrInvalidStorageKind):
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalidLoan),
+		errors.Is(err, services.ErrInvalidLoanStatus),
+		errors.Is(err, services.ErrInvalidMaintenanceStatus),
+		errors.Is(err, services.ErrInvalidDisposalReason),
+		errors.Is(err, services.ErrDisposalCondition):
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, services.ErrApprovedExceedsRequested),
+		errors.Is(err, services.ErrUnknownRequestLine),
+		errors.Is(err, services.ErrNothingApproved),
+		errors.Is(err, services.ErrRequestItemMismatch):
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalidLabelFormat),
+		errors.Is(err, services.ErrTooManyLabels),
+		errors.Is(err, label.ErrUnencodable):
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalidDate),
+		errors.Is(err, services.ErrInvalidCondition),
+		errors.Is(err, services.ErrInvalidCategory),
+		errors.Is(err, services.ErrInvalidRole),
+		errors.Is(err, services.ErrOPDRequired):
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+	default:
+		respondError(c, http.StatusInternalServerError, "Internal server error", err)
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid "+name, err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseDeleteParams reads the optional reassign_to query parameter of a
+// delete request
+func parseDeleteParams(c *gin.Context) (*uuid.UUID, bool) {
+	var params models.DeleteParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return nil, false
+	}
+	if params.ReassignTo == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(params.ReassignTo)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid reassign_to", err)
+		return nil, false
+	}
+	return &id, true
+}
+
+func parsePagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit
+}
+
+func totalPages(total int64, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(total) / float64(limit)))
+}
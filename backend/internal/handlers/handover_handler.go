@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetHandoverDocuments(c *gin.Context) {
+	var params models.HandoverSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	documents, total, err := h.svc.Handover.GetHandoverDocuments(currentScope(c), &params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       documents,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) CreateHandoverDocument(c *gin.Context) {
+	var req models.CreateHandoverDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if user := currentUser(c); user != nil {
+		req.IssuedBy = user.FullName
+		req.IssuedByID = &user.ID
+	}
+
+	document, err := h.svc.Handover.CreateHandoverDocument(currentScope(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+func (h *Handlers) GetHandoverDocument(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	document, err := h.svc.Handover.GetHandoverDocument(currentScope(c), id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+func (h *Handlers) GetHandoverDocumentPDF(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.svc.Handover.WriteHandoverPDF(currentScope(c), id.String(), &buf); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("BAST_%s.pdf", id)
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"warehouse-system/internal/models"
+	"warehouse-system/pkg/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportItems accepts a multipart upload with a "file" field holding a CSV or
+// XLSX file. Optional form fields: "mapping" (JSON object of field to column
+// header), "sheet" (XLSX sheet name) and "dry_run" (defaults to true).
+func (h *Handlers) ImportItems(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Form field 'file' is required", err)
+		return
+	}
+
+	format, err := spreadsheet.FormatFromFilename(fileHeader.Filename)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	opts := models.ImportOptions{DryRun: true}
+	if value := c.PostForm("dry_run"); value != "" {
+		if opts.DryRun, err = strconv.ParseBool(value); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid dry_run value", err)
+			return
+		}
+	}
+	if value := c.PostForm("mapping"); value != "" {
+		if err := json.Unmarshal([]byte(value), &opts.Mapping); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid mapping, expected a JSON object", err)
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Unable to read uploaded file", err)
+		return
+	}
+	defer file.Close()
+
+	rows, err := spreadsheet.ReadAll(file, format, c.PostForm("sheet"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Unable to parse uploaded file", err)
+		return
+	}
+
+	report, err := h.svc.Import.ImportItems(currentActor(c), rows, opts)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	switch {
+	case report.Committed:
+		c.JSON(http.StatusCreated, report)
+	case !report.DryRun && len(report.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, report)
+	default:
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetItems(c *gin.Context) {
+	var params models.ItemSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	items, total, err := h.svc.Item.GetItems(currentScope(c), &params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       items,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) CreateItem(c *gin.Context) {
+	var req models.CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	item, err := h.svc.Item.CreateItem(currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *Handlers) GetItem(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.Item.GetItem(currentScope(c), id)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handlers) UpdateItem(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	item, err := h.svc.Item.UpdateItem(currentActor(c), id, &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handlers) DeleteItem(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Item.DeleteItem(currentActor(c), id); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item deleted successfully"})
+}
+
+func (h *Handlers) SearchItems(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		respondError(c, http.StatusBadRequest, "Query parameter 'q' is required", nil)
+		return
+	}
+
+	items, err := h.svc.Item.SearchItems(currentScope(c), query)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *Handlers) GetDeletedItems(c *gin.Context) {
+	var params models.TrashSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	items, total, err := h.svc.Item.GetDeletedItems(&params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       items,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) RestoreItem(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.Item.RestoreItem(currentActor(c), id)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handlers) PurgeItem(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Item.PurgeItem(currentActor(c), id); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item purged permanently"})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetItemLabel(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var params models.LabelParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.svc.Label.WriteItemLabel(currentScope(c), id, &params, &buf); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	contentType := "image/png"
+	if strings.EqualFold(params.Format, "svg") {
+		contentType = "image/svg+xml"
+	}
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func (h *Handlers) GetItemLabelSheet(c *gin.Context) {
+	var search models.ItemSearchParams
+	if err := c.ShouldBindQuery(&search); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	var params models.LabelParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.svc.Label.WriteLabelSheet(currentScope(c), &search, &params, &buf); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("label_%s.pdf", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetLoans(c *gin.Context) {
+	var params models.LoanSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	h.listLoans(c, &params)
+}
+
+// GetOverdueLoans lists loans still out past their due date
+func (h *Handlers) GetOverdueLoans(c *gin.Context) {
+	var params models.LoanSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Status = models.LoanOverdue
+	h.listLoans(c, &params)
+}
+
+func (h *Handlers) listLoans(c *gin.Context, params *models.LoanSearchParams) {
+	params.Page, params.Limit = parsePagination(c)
+
+	loans, total, err := h.svc.Loan.GetLoans(currentScope(c), params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       loans,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetMaintenanceTickets(c *gin.Context) {
+	var params models.MaintenanceSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	tickets, total, err := h.svc.Maintenance.GetMaintenanceTickets(currentScope(c), &params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       tickets,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) CreateMaintenanceTicket(c *gin.Context) {
+	var req models.CreateMaintenanceTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	ticket, err := h.svc.Maintenance.CreateMaintenanceTicket(currentScope(c), currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ticket)
+}
+
+func (h *Handlers) GetMaintenanceTicket(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ticket, err := h.svc.Maintenance.GetMaintenanceTicket(currentScope(c), id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+func (h *Handlers) UpdateMaintenanceTicket(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMaintenanceTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	ticket, err := h.svc.Maintenance.UpdateMaintenanceTicket(id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+func (h *Handlers) CloseMaintenanceTicket(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CloseMaintenanceTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	ticket, err := h.svc.Maintenance.CloseMaintenanceTicket(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	contextUserKey    = "currentUser"
+	contextSessionKey = "sessionID"
+)
+
+// AuthRequired rejects requests without a valid bearer access token and
+// stores the authenticated user on the context
+func (h *Handlers) AuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			respondError(c, http.StatusUnauthorized, "Missing bearer token", nil)
+			return
+		}
+
+		user, sessionID, err := h.svc.Auth.Authenticate(token)
+		if err != nil {
+			handleServiceError(c, err)
+			return
+		}
+
+		c.Set(contextUserKey, user)
+		c.Set(contextSessionKey, sessionID)
+		c.Next()
+	}
+}
+
+// RequireRoles only lets users with one of the given roles through. It must
+// be registered after AuthRequired.
+func (h *Handlers) RequireRoles(roles ...models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := currentUser(c)
+		if user == nil {
+			respondError(c, http.StatusUnauthorized, "Authentication required", nil)
+			return
+		}
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+		respondError(c, http.StatusForbidden, "Your role does not allow this action", nil)
+	}
+}
+
+// currentUser returns the user set by AuthRequired
+func currentUser(c *gin.Context) *models.User {
+	if value, ok := c.Get(contextUserKey); ok {
+		if user, ok := value.(*models.User); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+// currentScope returns the data scope of the authenticated user. Requests
+// without a user get a scope that matches nothing.
+func currentScope(c *gin.Context) models.AccessScope {
+	if user := currentUser(c); user != nil {
+		return user.Scope()
+	}
+	return models.AccessScope{Restricted: true}
+}
+
+// currentActor identifies the authenticated user in audit records
+func currentActor(c *gin.Context) models.Actor {
+	if user := currentUser(c); user != nil {
+		return user.Actor()
+	}
+	return models.Actor{}
+}
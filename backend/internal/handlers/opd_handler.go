@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOPDs lists active OPDs, or with tree=true nests them under their
+// parents with item counts
+func (h *Handlers) GetOPDs(c *gin.Context) {
+	var params models.OPDSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	if params.Tree {
+		tree, err := h.svc.OPD.GetOPDTree()
+		if err != nil {
+			handleServiceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, tree)
+		return
+	}
+
+	opds, err := h.svc.OPD.GetOPDs()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, opds)
+}
+
+func (h *Handlers) CreateOPD(c *gin.Context) {
+	var req models.CreateOPDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	opd, err := h.svc.OPD.CreateOPD(currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, opd)
+}
+
+func (h *Handlers) UpdateOPD(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateOPDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	opd, err := h.svc.OPD.UpdateOPD(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, opd)
+}
+
+func (h *Handlers) MoveOPD(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.MoveOPDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	opd, err := h.svc.OPD.MoveOPD(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, opd)
+}
+
+func (h *Handlers) DeleteOPD(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	reassignTo, ok := parseDeleteParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.OPD.DeleteOPD(currentActor(c), id.String(), reassignTo); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "OPD deleted successfully"})
+}
+
+func (h *Handlers) GetDeletedOPDs(c *gin.Context) {
+	rows, err := h.svc.OPD.GetDeletedOPDs()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
+
+func (h *Handlers) RestoreOPD(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	row, err := h.svc.OPD.RestoreOPD(currentActor(c), id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, row)
+}
+
+func (h *Handlers) PurgeOPD(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.OPD.PurgeOPD(currentActor(c), id.String()); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "OPD purged permanently"})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetReorganizations(c *gin.Context) {
+	var params models.ReorganizationSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	reorganizations, total, err := h.svc.Reorganization.GetReorganizations(&params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       reorganizations,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) GetReorganization(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	reorganization, err := h.svc.Reorganization.GetReorganization(id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reorganization)
+}
+
+func (h *Handlers) MergeOPDs(c *gin.Context) {
+	var req models.MergeOPDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	reorganization, err := h.svc.Reorganization.MergeOPDs(currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reorganization)
+}
+
+func (h *Handlers) SplitOPD(c *gin.Context) {
+	var req models.SplitOPDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	reorganization, err := h.svc.Reorganization.SplitOPD(currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reorganization)
+}
+
+// GetOPDLineage lists the reorganizations an OPD took part in, as links to
+// the OPDs it was formed from or went into
+func (h *Handlers) GetOPDLineage(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	lineage, err := h.svc.Reorganization.GetLineage(id)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lineage)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetStorageLocationTree(c *gin.Context) {
+	var params models.StorageLocationSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	tree, err := h.svc.Storage.GetStorageLocationTree(currentScope(c), &params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *Handlers) GetStorageLocation(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	location, err := h.svc.Storage.GetStorageLocation(currentScope(c), id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+func (h *Handlers) CreateStorageLocation(c *gin.Context) {
+	var req models.CreateStorageLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	location, err := h.svc.Storage.CreateStorageLocation(currentScope(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, location)
+}
+
+func (h *Handlers) UpdateStorageLocation(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateStorageLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	location, err := h.svc.Storage.UpdateStorageLocation(currentScope(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+func (h *Handlers) DeleteStorageLocation(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Storage.DeleteStorageLocation(currentScope(c), id.String()); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Storage location deleted successfully"})
+}
+
+// GetStorageLocationItems lists the items anywhere under a storage location.
+// The item list filters apply as well.
+func (h *Handlers) GetStorageLocationItems(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var params models.ItemSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	scope := currentScope(c)
+	if _, err := h.svc.Storage.GetStorageLocation(scope, id.String()); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	params.LocationID = id.String()
+
+	items, total, err := h.svc.Item.GetItems(scope, &params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       items,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) MoveItems(c *gin.Context) {
+	var req models.MoveItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	moves, err := h.svc.Storage.MoveItems(currentScope(c), currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, moves)
+}
+
+func (h *Handlers) GetItemMoves(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	moves, err := h.svc.Storage.GetItemMoves(currentScope(c), id)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, moves)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetTransactions(c *gin.Context) {
+	var params models.TransactionSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	params.Page, params.Limit = parsePagination(c)
+
+	transactions, total, err := h.svc.Transaction.GetTransactions(currentScope(c), &params)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       transactions,
+		TotalCount: total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages(total, params.Limit),
+	})
+}
+
+func (h *Handlers) CreateTransaction(c *gin.Context) {
+	var req models.CreateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	stampProcessedBy(c, &req)
+
+	transaction, err := h.svc.Transaction.CreateTransaction(currentScope(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+func (h *Handlers) GetTransaction(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transaction, err := h.svc.Transaction.GetTransaction(currentScope(c), id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+func (h *Handlers) UpdateTransaction(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if user := currentUser(c); user != nil {
+		req.EditedBy = user.FullName
+		req.EditedByID = &user.ID
+	}
+
+	transaction, err := h.svc.Transaction.UpdateTransaction(id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+func (h *Handlers) ReverseTransaction(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ReverseTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if user := currentUser(c); user != nil {
+		req.ProcessedBy = user.FullName
+		req.ProcessedByID = &user.ID
+	}
+
+	transaction, err := h.svc.Transaction.ReverseTransaction(currentScope(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+func (h *Handlers) ConfirmReceipt(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	// The body is optional, a plain confirmation keeps the recorded condition
+	var req models.ConfirmReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	transaction, err := h.svc.Transaction.ConfirmReceipt(currentScope(c), currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+func (h *Handlers) RejectReceipt(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.RejectReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	transaction, err := h.svc.Transaction.RejectReceipt(currentScope(c), currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// stampProcessedBy records the authenticated user as the operator of a
+// transaction request
+func stampProcessedBy(c *gin.Context, req *models.CreateTransactionRequest) {
+	if user := currentUser(c); user != nil {
+		req.ProcessedBy = user.FullName
+		req.ProcessedByID = &user.ID
+	}
+}
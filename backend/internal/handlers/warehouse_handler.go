@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"warehouse-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handlers) GetWarehouses(c *gin.Context) {
+	warehouses, err := h.svc.Warehouse.GetWarehouses()
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, warehouses)
+}
+
+func (h *Handlers) GetWarehouse(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	warehouse, err := h.svc.Warehouse.GetWarehouse(id.String())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, warehouse)
+}
+
+func (h *Handlers) CreateWarehouse(c *gin.Context) {
+	var req models.CreateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	warehouse, err := h.svc.Warehouse.CreateWarehouse(currentActor(c), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, warehouse)
+}
+
+func (h *Handlers) UpdateWarehouse(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	warehouse, err := h.svc.Warehouse.UpdateWarehouse(currentActor(c), id.String(), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, warehouse)
+}
+
+func (h *Handlers) DeleteWarehouse(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Warehouse.DeleteWarehouse(currentActor(c), id.String()); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Warehouse deleted successfully"})
+}
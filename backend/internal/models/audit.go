@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditEntityType string
+
+const (
+	AuditEntityItem        AuditEntityType = "item"
+	AuditEntityOPD         AuditEntityType = "opd"
+	AuditEntityCategory    AuditEntityType = "category"
+	AuditEntityTransaction AuditEntityType = "transaction"
+	AuditEntityWarehouse   AuditEntityType = "warehouse"
+	AuditEntityCustodian   AuditEntityType = "custodian"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+	AuditActionPurge   AuditAction = "purge"
+	AuditActionReverse AuditAction = "reverse"
+)
+
+// AuditLog is one change to an audited entity. Changes maps each changed
+// field to its old and new value.
+type AuditLog struct {
+	ID         uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"index"`
+	ActorID    *uuid.UUID      `json:"actor_id" gorm:"type:uuid;index"`
+	ActorName  string          `json:"actor_name"`
+	EntityType AuditEntityType `json:"entity_type" gorm:"not null;index:idx_audit_entity"`
+	EntityID   uuid.UUID       `json:"entity_id" gorm:"type:uuid;not null;index:idx_audit_entity"`
+	Action     AuditAction     `json:"action" gorm:"not null"`
+	Changes    json.RawMessage `json:"changes" gorm:"type:jsonb"`
+}
+
+// FieldChange is the before/after pair stored for one field
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Actor identifies who performed a change
+type Actor struct {
+	ID   *uuid.UUID
+	Name string
+}
+
+type AuditSearchParams struct {
+	EntityType string `form:"entity_type"`
+	EntityID   string `form:"entity_id"`
+	ActorID    string `form:"actor_id"`
+	From       string `form:"from"`
+	To         string `form:"to"`
+	Page       int    `form:"page"`
+	Limit      int    `form:"limit"`
+}
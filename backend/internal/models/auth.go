@@ -0,0 +1,170 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserRole string
+
+const (
+	RoleWarehouseAdmin    UserRole = "warehouse_admin"
+	RoleWarehouseOperator UserRole = "warehouse_operator"
+	RoleOPDCustodian      UserRole = "opd_custodian"
+	RoleAuditor           UserRole = "auditor"
+)
+
+func (r UserRole) IsValid() bool {
+	switch r {
+	case RoleWarehouseAdmin, RoleWarehouseOperator, RoleOPDCustodian, RoleAuditor:
+		return true
+	}
+	return false
+}
+
+// User is an account that can sign in to the API
+type User struct {
+	BaseModel
+	Username     string     `json:"username" gorm:"not null;uniqueIndex"`
+	PasswordHash string     `json:"-" gorm:"not null"`
+	FullName     string     `json:"full_name" gorm:"not null"`
+	Role         UserRole   `json:"role" gorm:"not null;default:'auditor'"`
+	OPDID        *uuid.UUID `json:"opd_id" gorm:"type:uuid"`
+	OPD          *OPD       `json:"opd,omitempty" gorm:"foreignKey:OPDID"`
+	IsActive     bool       `json:"is_active" gorm:"default:true"`
+}
+
+func (u *User) Actor() Actor {
+	return Actor{ID: &u.ID, Name: u.FullName}
+}
+
+func (u *User) IsWarehouseStaff() bool {
+	return u.Role == RoleWarehouseAdmin || u.Role == RoleWarehouseOperator
+}
+
+// Scope returns the data the user is allowed to see. OPD custodians are
+// limited to their own OPD; every other role sees everything.
+func (u *User) Scope() AccessScope {
+	if u.Role != RoleOPDCustodian {
+		return AccessScope{}
+	}
+	return AccessScope{Restricted: true, OPDID: u.OPDID}
+}
+
+// AccessScope restricts repository queries to the items held by one OPD.
+// A restricted scope without an OPD matches nothing.
+type AccessScope struct {
+	Restricted bool
+	OPDID      *uuid.UUID
+}
+
+// AllowsItem reports whether an already loaded item is inside the scope
+func (s AccessScope) AllowsItem(item *Item) bool {
+	if !s.Restricted {
+		return true
+	}
+	return s.OPDID != nil && item.CurrentLocation == LocationOPD &&
+		item.CurrentOPDID != nil && *item.CurrentOPDID == *s.OPDID
+}
+
+// UserSession backs a refresh token. Access tokens carry the session ID so
+// that revoking the session also invalidates them.
+type UserSession struct {
+	BaseModel
+	UserID           uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User             User       `json:"-" gorm:"foreignKey:UserID"`
+	RefreshTokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt        time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// AllowsTransaction reports whether a movement into or out of the scope's OPD
+func (s AccessScope) AllowsTransaction(t *Transaction) bool {
+	if !s.Restricted {
+		return true
+	}
+	if s.OPDID == nil {
+		return false
+	}
+	return (t.SourceOPDID != nil && *t.SourceOPDID == *s.OPDID) ||
+		(t.TargetOPDID != nil && *t.TargetOPDID == *s.OPDID)
+}
+
+// AllowsHandover reports whether a handover document involves the scope's OPD
+func (s AccessScope) AllowsHandover(d *HandoverDocument) bool {
+	return s.AllowsTransaction(&Transaction{SourceOPDID: d.SourceOPDID, TargetOPDID: d.TargetOPDID})
+}
+
+// AllowsTransferBatch reports whether a batch moves items into or out of the
+// scope's OPD
+func (s AccessScope) AllowsTransferBatch(b *TransferBatch) bool {
+	return s.AllowsTransaction(&Transaction{SourceOPDID: b.SourceOPDID, TargetOPDID: b.TargetOPDID})
+}
+
+// AllowsItemRequest reports whether a request was made by the scope's OPD
+func (s AccessScope) AllowsItemRequest(r *ItemRequest) bool {
+	if !s.Restricted {
+		return true
+	}
+	return s.OPDID != nil && r.OPDID == *s.OPDID
+}
+
+// AllowsMaintenanceTicket reports whether a ticket was reported for an item
+// held by the scope's OPD
+func (s AccessScope) AllowsMaintenanceTicket(t *MaintenanceTicket) bool {
+	if !s.Restricted {
+		return true
+	}
+	return s.OPDID != nil && t.OPDID != nil && *t.OPDID == *s.OPDID
+}
+
+// AllowsStockOpname reports whether a count is of the scope's OPD
+func (s AccessScope) AllowsStockOpname(o *StockOpname) bool {
+	if !s.Restricted {
+		return true
+	}
+	return s.OPDID != nil && o.Location == LocationOPD && o.OPDID != nil && *o.OPDID == *s.OPDID
+}
+
+// AllowsCustodian reports whether a custodian works in the scope's OPD
+func (s AccessScope) AllowsCustodian(c *Custodian) bool {
+	if !s.Restricted {
+		return true
+	}
+	return s.OPDID != nil && c.OPDID == *s.OPDID
+}
+
+// AllowsStorageLocation reports whether a storage location belongs to the
+// scope's OPD
+func (s AccessScope) AllowsStorageLocation(l *StorageLocation) bool {
+	if !s.Restricted {
+		return true
+	}
+	return l.IsAt(LocationOPD, s.OPDID)
+}
+
+type CreateUserRequest struct {
+	Username string     `json:"username" binding:"required"`
+	Password string     `json:"password" binding:"required,min=8"`
+	FullName string     `json:"full_name" binding:"required"`
+	Role     UserRole   `json:"role" binding:"required"`
+	OPDID    *uuid.UUID `json:"opd_id"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	User         User   `json:"user"`
+}
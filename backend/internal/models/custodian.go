@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Custodian is the employee responsible (penanggung jawab) for the items
+// assigned to them. A custodian works in one OPD and is only responsible
+// for items held by it. NIPs are unique among active custodians.
+type Custodian struct {
+	BaseModel
+	Name     string    `json:"name" gorm:"not null"`
+	NIP      string    `json:"nip" gorm:"not null;uniqueIndex:idx_custodians_active_nip,where:is_active = true"`
+	Position string    `json:"position"`
+	OPDID    uuid.UUID `json:"opd_id" gorm:"type:uuid;not null;index"`
+	OPD      *OPD      `json:"opd,omitempty" gorm:"foreignKey:OPDID"`
+	SoftDelete
+}
+
+// CreateCustodianRequest creates or updates a custodian. Moving a custodian
+// to another OPD requires their items to be reassigned first.
+type CreateCustodianRequest struct {
+	Name     string    `json:"name" binding:"required"`
+	NIP      string    `json:"nip" binding:"required"`
+	Position string    `json:"position"`
+	OPDID    uuid.UUID `json:"opd_id" binding:"required"`
+}
+
+// AssignCustodianRequest makes a custodian of the OPD holding an item
+// responsible for it, or nobody when CustodianID is empty
+type AssignCustodianRequest struct {
+	CustodianID *uuid.UUID `json:"custodian_id"`
+}
+
+type CustodianSearchParams struct {
+	Query string `form:"q"`
+	OPDID string `form:"opd_id"`
+	Page  int    `form:"page"`
+	Limit int    `form:"limit"`
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DisposalReason string
+
+const (
+	DisposalDamaged DisposalReason = "damaged"
+	DisposalLost    DisposalReason = "lost"
+	DisposalSold    DisposalReason = "sold"
+	DisposalGrant   DisposalReason = "grant"
+)
+
+func (r DisposalReason) IsValid() bool {
+	switch r {
+	case DisposalDamaged, DisposalLost, DisposalSold, DisposalGrant:
+		return true
+	}
+	return false
+}
+
+type DisposalStatus string
+
+const (
+	DisposalProposed DisposalStatus = "proposed"
+	DisposalApproved DisposalStatus = "approved"
+	DisposalRejected DisposalStatus = "rejected"
+	DisposalExecuted DisposalStatus = "executed"
+)
+
+type DisposalDecision string
+
+const (
+	DisposalDecisionApprove DisposalDecision = "approved"
+	DisposalDecisionReject  DisposalDecision = "rejected"
+)
+
+// Disposal is a proposal to write off (penghapusan) a set of items. It
+// needs ApprovalsRequired approvals from different admins and is executed
+// once the decree (SK penghapusan) is issued, which moves its items to the
+// terminal Dihapuskan location.
+type Disposal struct {
+	BaseModel
+	Reason            DisposalReason     `json:"reason" gorm:"not null"`
+	Status            DisposalStatus     `json:"status" gorm:"not null;default:'proposed';index"`
+	Notes             string             `json:"notes"`
+	ItemCount         int                `json:"item_count"`
+	ApprovalsRequired int                `json:"approvals_required"`
+	ProposedBy        string             `json:"proposed_by"`
+	ProposedByID      *uuid.UUID         `json:"proposed_by_id" gorm:"type:uuid"`
+	DecreeNumber      string             `json:"decree_number" gorm:"index"`
+	DecreeDate        *time.Time         `json:"decree_date"`
+	ExecutedAt        *time.Time         `json:"executed_at"`
+	ExecutedBy        string             `json:"executed_by"`
+	ExecutedByID      *uuid.UUID         `json:"executed_by_id" gorm:"type:uuid"`
+	Items             []DisposalItem     `json:"items,omitempty" gorm:"foreignKey:DisposalID"`
+	Approvals         []DisposalApproval `json:"approvals,omitempty" gorm:"foreignKey:DisposalID"`
+}
+
+// DisposalItem is one item of a proposal, with its state when proposed
+type DisposalItem struct {
+	BaseModel
+	DisposalID   uuid.UUID    `json:"disposal_id" gorm:"type:uuid;not null;uniqueIndex:idx_disposal_item"`
+	ItemID       uuid.UUID    `json:"item_id" gorm:"type:uuid;not null;uniqueIndex:idx_disposal_item;index"`
+	Item         *Item        `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	SerialNumber string       `json:"serial_number"`
+	Condition    Condition    `json:"condition"`
+	Location     LocationType `json:"location"`
+	OPDID        *uuid.UUID   `json:"opd_id" gorm:"type:uuid"`
+}
+
+// DisposalApproval is one review step of a proposal
+type DisposalApproval struct {
+	BaseModel
+	DisposalID uuid.UUID        `json:"disposal_id" gorm:"type:uuid;not null;index"`
+	Step       int              `json:"step"`
+	Decision   DisposalDecision `json:"decision" gorm:"not null"`
+	Comment    string           `json:"comment"`
+	Approver   string           `json:"approver"`
+	ApproverID *uuid.UUID       `json:"approver_id" gorm:"type:uuid"`
+}
+
+type CreateDisposalRequest struct {
+	Reason  DisposalReason `json:"reason" binding:"required"`
+	ItemIDs []uuid.UUID    `json:"item_ids" binding:"required,min=1"`
+	Notes   string         `json:"notes"`
+}
+
+type ApproveDisposalRequest struct {
+	Comment string `json:"comment"`
+}
+
+type RejectDisposalRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+// ExecuteDisposalRequest carries the decree that makes an approved
+// disposal final. DecreeDate is YYYY-MM-DD or RFC 3339.
+type ExecuteDisposalRequest struct {
+	DecreeNumber string `json:"decree_number" binding:"required"`
+	DecreeDate   string `json:"decree_date" binding:"required"`
+}
+
+type DisposalSearchParams struct {
+	Status string `form:"status"`
+	Reason string `form:"reason"`
+	ItemID string `form:"item_id"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentSequence holds the last number issued for a kind of document in a
+// year. Numbers restart at 1 every year.
+type DocumentSequence struct {
+	Kind       string `gorm:"primaryKey"`
+	Year       int    `gorm:"primaryKey;autoIncrement:false"`
+	LastNumber int    `gorm:"not null"`
+}
+
+// HandoverDocument is a Berita Acara Serah Terima (BAST) for one or more
+// movements sharing a direction, source and target. Item details are copied
+// into the lines so that a reprint matches the signed original.
+type HandoverDocument struct {
+	BaseModel
+	Number            string                 `json:"number" gorm:"not null;uniqueIndex"`
+	Year              int                    `json:"year" gorm:"not null"`
+	Sequence          int                    `json:"sequence" gorm:"not null"`
+	Direction         TransactionDirection   `json:"direction" gorm:"not null"`
+	SourceOPDID       *uuid.UUID             `json:"source_opd_id" gorm:"type:uuid"`
+	SourceOPD         *OPD                   `json:"source_opd,omitempty" gorm:"foreignKey:SourceOPDID"`
+	TargetOPDID       *uuid.UUID             `json:"target_opd_id" gorm:"type:uuid"`
+	TargetOPD         *OPD                   `json:"target_opd,omitempty" gorm:"foreignKey:TargetOPDID"`
+	SourceWarehouseID *uuid.UUID             `json:"source_warehouse_id" gorm:"type:uuid"`
+	SourceWarehouse   *Warehouse             `json:"source_warehouse,omitempty" gorm:"foreignKey:SourceWarehouseID"`
+	TargetWarehouseID *uuid.UUID             `json:"target_warehouse_id" gorm:"type:uuid"`
+	TargetWarehouse   *Warehouse             `json:"target_warehouse,omitempty" gorm:"foreignKey:TargetWarehouseID"`
+	SpecificLocation  string                 `json:"specific_location"`
+	HandoverDate      time.Time              `json:"handover_date" gorm:"not null"`
+	ProcessedBy       string                 `json:"processed_by"`
+	IssuedBy          string                 `json:"issued_by"`
+	IssuedByID        *uuid.UUID             `json:"issued_by_id" gorm:"type:uuid"`
+	Lines             []HandoverDocumentLine `json:"lines,omitempty" gorm:"foreignKey:DocumentID"`
+}
+
+// HandoverDocumentLine is one transaction on a BAST. A transaction can only
+// appear on one document.
+type HandoverDocumentLine struct {
+	BaseModel
+	DocumentID    uuid.UUID `json:"document_id" gorm:"type:uuid;not null;index"`
+	TransactionID uuid.UUID `json:"transaction_id" gorm:"type:uuid;not null;uniqueIndex"`
+	ItemID        uuid.UUID `json:"item_id" gorm:"type:uuid;not null"`
+	SerialNumber  string    `json:"serial_number" gorm:"not null"`
+	Brand         string    `json:"brand"`
+	Model         string    `json:"model"`
+	CategoryName  string    `json:"category_name"`
+	Condition     Condition `json:"condition"`
+}
+
+// CreateHandoverDocumentRequest names the transactions to document, either
+// directly or as every transaction of a transfer batch
+type CreateHandoverDocumentRequest struct {
+	TransactionIDs []uuid.UUID `json:"transaction_ids"`
+	BatchID        *uuid.UUID  `json:"batch_id"`
+	// IssuedBy is stamped from the authenticated user, never from the body
+	IssuedBy   string     `json:"-"`
+	IssuedByID *uuid.UUID `json:"-"`
+}
+
+type HandoverSearchParams struct {
+	OPDID string `form:"opd_id"`
+	From  string `form:"from"`
+	To    string `form:"to"`
+	Page  int    `form:"page"`
+	Limit int    `form:"limit"`
+}
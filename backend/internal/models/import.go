@@ -0,0 +1,39 @@
+package models
+
+// Fields an item import can map spreadsheet columns to
+const (
+	ImportFieldSerialNumber     = "serial_number"
+	ImportFieldCategory         = "category"
+	ImportFieldBrand            = "brand"
+	ImportFieldModel            = "model"
+	ImportFieldCondition        = "condition"
+	ImportFieldDescription      = "description"
+	ImportFieldOPD              = "opd"
+	ImportFieldSpecificLocation = "specific_location"
+)
+
+// ImportOptions controls an item import. Mapping maps an import field to the
+// header of the column holding it; unmapped fields fall back to their
+// default headers.
+type ImportOptions struct {
+	Mapping map[string]string `json:"mapping"`
+	DryRun  bool              `json:"dry_run"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport describes the outcome of an import. Rows are numbered as in
+// the spreadsheet, so the first data row below the header is row 2.
+type ImportReport struct {
+	DryRun       bool             `json:"dry_run"`
+	Committed    bool             `json:"committed"`
+	TotalRows    int              `json:"total_rows"`
+	ValidRows    int              `json:"valid_rows"`
+	ImportedRows int              `json:"imported_rows"`
+	Errors       []ImportRowError `json:"errors"`
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type ItemRequestStatus string
+
+const (
+	ItemRequestPending           ItemRequestStatus = "pending"
+	ItemRequestApproved          ItemRequestStatus = "approved"
+	ItemRequestPartiallyApproved ItemRequestStatus = "partially_approved"
+	ItemRequestRejected          ItemRequestStatus = "rejected"
+	ItemRequestCancelled         ItemRequestStatus = "cancelled"
+	ItemRequestFulfilled         ItemRequestStatus = "fulfilled"
+)
+
+type ItemRequestAction string
+
+const (
+	ItemRequestSubmitted         ItemRequestAction = "submitted"
+	ItemRequestActionApprove     ItemRequestAction = "approved"
+	ItemRequestActionPartial     ItemRequestAction = "partially_approved"
+	ItemRequestActionReject      ItemRequestAction = "rejected"
+	ItemRequestActionCancel      ItemRequestAction = "cancelled"
+	ItemRequestActionDeliver     ItemRequestAction = "delivered"
+	ItemRequestActionFulfillment ItemRequestAction = "fulfilled"
+	ItemRequestActionReturn      ItemRequestAction = "returned"
+)
+
+// ItemRequest is an OPD asking the warehouse for items by category, e.g.
+// five laptops. Approved quantities are delivered as transfer batches whose
+// transactions reference the request.
+type ItemRequest struct {
+	BaseModel
+	OPDID         uuid.UUID          `json:"opd_id" gorm:"type:uuid;not null;index"`
+	OPD           *OPD               `json:"opd,omitempty" gorm:"foreignKey:OPDID"`
+	Status        ItemRequestStatus  `json:"status" gorm:"not null;default:'pending';index"`
+	Notes         string             `json:"notes"`
+	RequestedBy   string             `json:"requested_by"`
+	RequestedByID *uuid.UUID         `json:"requested_by_id" gorm:"type:uuid"`
+	Lines         []ItemRequestLine  `json:"lines,omitempty" gorm:"foreignKey:RequestID"`
+	Events        []ItemRequestEvent `json:"events,omitempty" gorm:"foreignKey:RequestID"`
+}
+
+// ItemRequestLine asks for a quantity of one category
+type ItemRequestLine struct {
+	BaseModel
+	RequestID         uuid.UUID `json:"request_id" gorm:"type:uuid;not null;index"`
+	CategoryID        uuid.UUID `json:"category_id" gorm:"type:uuid;not null"`
+	Category          *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	Quantity          int       `json:"quantity" gorm:"not null"`
+	ApprovedQuantity  int       `json:"approved_quantity"`
+	DeliveredQuantity int       `json:"delivered_quantity"`
+	Notes             string    `json:"notes"`
+}
+
+// Outstanding is how many approved items are still to be delivered
+func (l *ItemRequestLine) Outstanding() int {
+	return l.ApprovedQuantity - l.DeliveredQuantity
+}
+
+// ItemRequestEvent is one step in the history of a request
+type ItemRequestEvent struct {
+	BaseModel
+	RequestID uuid.UUID         `json:"request_id" gorm:"type:uuid;not null;index"`
+	Action    ItemRequestAction `json:"action" gorm:"not null"`
+	Comment   string            `json:"comment"`
+	BatchID   *uuid.UUID        `json:"batch_id" gorm:"type:uuid"`
+	Actor     string            `json:"actor"`
+	ActorID   *uuid.UUID        `json:"actor_id" gorm:"type:uuid"`
+}
+
+type ItemRequestLineInput struct {
+	CategoryID uuid.UUID `json:"category_id" binding:"required"`
+	Quantity   int       `json:"quantity" binding:"required,min=1"`
+	Notes      string    `json:"notes"`
+}
+
+// CreateItemRequestRequest submits a request. OPD custodians always request
+// for their own OPD; warehouse staff name the OPD.
+type CreateItemRequestRequest struct {
+	OPDID *uuid.UUID             `json:"opd_id"`
+	Notes string                 `json:"notes"`
+	Lines []ItemRequestLineInput `json:"lines" binding:"required,min=1,dive"`
+}
+
+type ApproveLineInput struct {
+	LineID           uuid.UUID `json:"line_id" binding:"required"`
+	ApprovedQuantity int       `json:"approved_quantity" binding:"min=0"`
+}
+
+// ApproveItemRequestRequest approves a pending request. Lines left out are
+// approved in full; approving less than requested makes it partial.
+type ApproveItemRequestRequest struct {
+	Lines   []ApproveLineInput `json:"lines" binding:"dive"`
+	Comment string             `json:"comment"`
+}
+
+type ItemRequestCommentRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+// FulfillItemRequestRequest delivers approved items from the warehouse. The
+// items must match outstanding quantities of the request's categories.
+type FulfillItemRequestRequest struct {
+	ItemIDs          []uuid.UUID `json:"item_ids" binding:"required,min=1"`
+	SpecificLocation string      `json:"specific_location"`
+	LocationID       *uuid.UUID  `json:"location_id"`
+	Notes            string      `json:"notes"`
+	RequireReceipt   bool        `json:"require_receipt"`
+}
+
+type ItemRequestSearchParams struct {
+	Status string `form:"status"`
+	OPDID  string `form:"opd_id"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
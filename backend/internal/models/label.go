@@ -0,0 +1,15 @@
+package models
+
+// LabelContent selects which identifier a label's symbol encodes
+type LabelContent string
+
+const (
+	LabelContentSerial LabelContent = "serial"
+	LabelContentID     LabelContent = "id"
+)
+
+type LabelParams struct {
+	Type    string       `form:"type"`
+	Content LabelContent `form:"content"`
+	Format  string       `form:"format"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// LoanStatus filters loans. A loan is a Gudang → OPD transaction with a due
+// date; it is returned by the next OPD → Gudang movement of the item.
+type LoanStatus string
+
+const (
+	LoanOpen     LoanStatus = "open"
+	LoanOverdue  LoanStatus = "overdue"
+	LoanReturned LoanStatus = "returned"
+)
+
+func (s LoanStatus) IsValid() bool {
+	switch s {
+	case LoanOpen, LoanOverdue, LoanReturned:
+		return true
+	}
+	return false
+}
+
+type LoanSearchParams struct {
+	Status LoanStatus `form:"status"`
+	OPDID  string     `form:"opd_id"`
+	ItemID string     `form:"item_id"`
+	Page   int        `form:"page"`
+	Limit  int        `form:"limit"`
+}
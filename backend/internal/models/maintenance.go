@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MaintenanceStatus string
+
+const (
+	MaintenanceReported     MaintenanceStatus = "reported"
+	MaintenanceInRepair     MaintenanceStatus = "in_repair"
+	MaintenanceRepaired     MaintenanceStatus = "repaired"
+	MaintenanceUnrepairable MaintenanceStatus = "unrepairable"
+)
+
+// IsOpen reports whether a ticket with this status still holds its item
+func (s MaintenanceStatus) IsOpen() bool {
+	return s == MaintenanceReported || s == MaintenanceInRepair
+}
+
+// MaintenanceTicket tracks a reported issue with an item through repair.
+// An item has at most one open ticket and cannot be moved while it is open;
+// closing the ticket sets the item's condition.
+type MaintenanceTicket struct {
+	BaseModel
+	ItemID          uuid.UUID         `json:"item_id" gorm:"type:uuid;not null;index"`
+	Item            *Item             `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	OPDID           *uuid.UUID        `json:"opd_id" gorm:"type:uuid;index"`
+	OPD             *OPD              `json:"opd,omitempty" gorm:"foreignKey:OPDID"`
+	Status          MaintenanceStatus `json:"status" gorm:"not null;default:'reported';index"`
+	Issue           string            `json:"issue" gorm:"not null"`
+	ConditionBefore Condition         `json:"condition_before"`
+	ConditionAfter  Condition         `json:"condition_after"`
+	Vendor          string            `json:"vendor"`
+	// Cost of the repair in rupiah
+	Cost            int64      `json:"cost"`
+	Notes           string     `json:"notes"`
+	Resolution      string     `json:"resolution"`
+	ReportedBy      string     `json:"reported_by"`
+	ReportedByID    *uuid.UUID `json:"reported_by_id" gorm:"type:uuid"`
+	RepairStartedAt *time.Time `json:"repair_started_at"`
+	ClosedAt        *time.Time `json:"closed_at"`
+	ClosedBy        string     `json:"closed_by"`
+	ClosedByID      *uuid.UUID `json:"closed_by_id" gorm:"type:uuid"`
+}
+
+// CreateMaintenanceTicketRequest reports an issue. Condition, when given,
+// records the damage found and updates the item right away.
+type CreateMaintenanceTicketRequest struct {
+	ItemID    uuid.UUID `json:"item_id" binding:"required"`
+	Issue     string    `json:"issue" binding:"required"`
+	Condition Condition `json:"condition"`
+	Vendor    string    `json:"vendor"`
+}
+
+// UpdateMaintenanceTicketRequest edits an open ticket. Status can only move
+// a reported ticket into repair; use the close endpoint to finish it.
+type UpdateMaintenanceTicketRequest struct {
+	Status MaintenanceStatus `json:"status"`
+	Vendor *string           `json:"vendor"`
+	Cost   *int64            `json:"cost" binding:"omitempty,min=0"`
+	Notes  *string           `json:"notes"`
+}
+
+// CloseMaintenanceTicketRequest finishes a ticket. A repaired item gets
+// Condition (Layak pakai by default); an unrepairable one Rusak/Hilang.
+type CloseMaintenanceTicketRequest struct {
+	Status     MaintenanceStatus `json:"status" binding:"required"`
+	Condition  Condition         `json:"condition"`
+	Cost       *int64            `json:"cost" binding:"omitempty,min=0"`
+	Resolution string            `json:"resolution"`
+}
+
+type MaintenanceSearchParams struct {
+	Status string `form:"status"`
+	ItemID string `form:"item_id"`
+	OPDID  string `form:"opd_id"`
+	Open   bool   `form:"open"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,11 +16,26 @@ const (
 	ConditionBroken  Condition = "Rusak/Hilang"
 )
 
+// Conditions lists every valid item condition in display order
+var Conditions = []Condition{ConditionGood, ConditionPartial, ConditionBroken}
+
+func (c Condition) IsValid() bool {
+	for _, condition := range Conditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
 type LocationType string
 
 const (
 	LocationWarehouse LocationType = "Gudang"
 	LocationOPD       LocationType = "OPD"
+	LocationInTransit LocationType = "Dalam Pengiriman"
+	// LocationDisposed is terminal: the item was written off by a disposal
+	LocationDisposed LocationType = "Dihapuskan"
 )
 
 type TransactionDirection string
@@ -28,66 +44,225 @@ const (
 	DirectionWarehouseToOPD TransactionDirection = "Gudang → OPD"
 	DirectionOPDToWarehouse TransactionDirection = "OPD → Gudang"
 	DirectionOPDToOPD       TransactionDirection = "OPD → OPD"
+	// DirectionWarehouseToWarehouse transfers stock between warehouses
+	DirectionWarehouseToWarehouse TransactionDirection = "Gudang → Gudang"
+)
+
+// FromWarehouse reports whether movements in this direction leave a warehouse
+func (d TransactionDirection) FromWarehouse() bool {
+	return d == DirectionWarehouseToOPD || d == DirectionWarehouseToWarehouse
+}
+
+// ToWarehouse reports whether movements in this direction end in a warehouse
+func (d TransactionDirection) ToWarehouse() bool {
+	return d == DirectionOPDToWarehouse || d == DirectionWarehouseToWarehouse
+}
+
+// TransactionStatus tracks whether a movement has taken effect. Movements
+// that need a receipt stay in transit until the receiving side confirms,
+// rejects, or the receipt deadline passes.
+type TransactionStatus string
+
+const (
+	TransactionCompleted TransactionStatus = "completed"
+	TransactionInTransit TransactionStatus = "in_transit"
+	TransactionRejected  TransactionStatus = "rejected"
+	TransactionExpired   TransactionStatus = "expired"
 )
 
-// Base model with UUID
+// Base model with UUID. Rows are never soft-deleted through gorm; entities
+// that can be deleted embed SoftDelete instead.
 type BaseModel struct {
-	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SoftDelete is the one deactivation model of items, OPDs and categories.
+// Deleting moves a row to the trash: IsActive is cleared and who deleted it
+// and when is recorded. The row stays, so history that refers to it still
+// resolves, until it is restored or purged.
+type SoftDelete struct {
+	IsActive    bool       `json:"is_active" gorm:"default:true;index"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	DeletedBy   string     `json:"deleted_by"`
+	DeletedByID *uuid.UUID `json:"deleted_by_id" gorm:"type:uuid"`
+}
+
+// MarkDeleted moves the row to the trash
+func (d *SoftDelete) MarkDeleted(actor Actor, at time.Time) {
+	d.IsActive = false
+	d.DeletedAt = &at
+	d.DeletedBy = actor.Name
+	d.DeletedByID = actor.ID
+}
+
+// Restore takes the row out of the trash
+func (d *SoftDelete) Restore() {
+	d.IsActive = true
+	d.DeletedAt = nil
+	d.DeletedBy = ""
+	d.DeletedByID = nil
 }
 
-// OPD represents organizational units
+// Active is the SoftDelete of a newly created row
+func Active() SoftDelete {
+	return SoftDelete{IsActive: true}
+}
+
+// OPD represents organizational units. Names are unique among active OPDs,
+// so a deleted OPD's name can be reused. OPDs form a tree: a dinas holds
+// its bidang and a bidang its seksi. Path lists the OPD IDs from the root
+// down to the OPD, so the units under an OPD are a single prefix match.
 type OPD struct {
 	BaseModel
-	Name        string `json:"name" gorm:"not null;uniqueIndex"`
-	Description string `json:"description"`
-	IsActive    bool   `json:"is_active" gorm:"default:true"`
+	ParentID    *uuid.UUID `json:"parent_id" gorm:"type:uuid;index"`
+	Name        string     `json:"name" gorm:"not null;uniqueIndex:idx_opds_active_name,where:is_active = true"`
+	Description string     `json:"description"`
+	Path        string     `json:"path" gorm:"not null;default:'';index"`
+	SoftDelete
+}
+
+// PathIDs returns the IDs of the OPD's ancestors followed by its own
+func (o *OPD) PathIDs() []uuid.UUID {
+	var ids []uuid.UUID
+	for _, part := range strings.Split(strings.Trim(o.Path, "/"), "/") {
+		if id, err := uuid.Parse(part); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// OPDNode is an OPD in a tree response. ItemCount counts the items held by
+// the OPD itself, TotalItems those held anywhere under it.
+type OPDNode struct {
+	OPD
+	ItemCount  int64      `json:"item_count"`
+	TotalItems int64      `json:"total_items"`
+	Children   []*OPDNode `json:"children"`
 }
 
 // Category represents item categories
 type Category struct {
 	BaseModel
-	Name        string `json:"name" gorm:"not null;uniqueIndex"`
+	Name        string `json:"name" gorm:"not null;uniqueIndex:idx_categories_active_name,where:is_active = true"`
 	Description string `json:"description"`
-	IsActive    bool   `json:"is_active" gorm:"default:true"`
+	SoftDelete
 }
 
-// Item represents inventory items
+// Item represents inventory items. Serial numbers stay unique across
+// deleted items too: a deleted asset is restored, not registered again.
 type Item struct {
 	BaseModel
-	SerialNumber     string       `json:"serial_number" gorm:"not null;uniqueIndex"`
-	CategoryID       uuid.UUID    `json:"category_id" gorm:"not null"`
-	Category         Category     `json:"category" gorm:"foreignKey:CategoryID"`
-	Brand            string       `json:"brand" gorm:"not null"`
-	Model            string       `json:"model" gorm:"not null"`
-	Condition        Condition    `json:"condition" gorm:"not null"`
-	Description      string       `json:"description"`
-	EntryDate        *time.Time   `json:"entry_date"`
-	ExitDate         *time.Time   `json:"exit_date"`
-	CurrentLocation  LocationType `json:"current_location" gorm:"not null;default:'Gudang'"`
-	CurrentOPDID     *uuid.UUID   `json:"current_opd_id"`
-	CurrentOPD       *OPD         `json:"current_opd" gorm:"foreignKey:CurrentOPDID"`
-	SpecificLocation string       `json:"specific_location"`
-	IsActive         bool         `json:"is_active" gorm:"default:true"`
-	Transactions     []Transaction `json:"transactions,omitempty" gorm:"foreignKey:ItemID"`
-}
-
-// Transaction represents item movements
+	SerialNumber    string       `json:"serial_number" gorm:"not null;uniqueIndex"`
+	CategoryID      uuid.UUID    `json:"category_id" gorm:"not null"`
+	Category        Category     `json:"category" gorm:"foreignKey:CategoryID"`
+	Brand           string       `json:"brand" gorm:"not null"`
+	Model           string       `json:"model" gorm:"not null"`
+	Condition       Condition    `json:"condition" gorm:"not null"`
+	Description     string       `json:"description"`
+	EntryDate       *time.Time   `json:"entry_date"`
+	ExitDate        *time.Time   `json:"exit_date"`
+	CurrentLocation LocationType `json:"current_location" gorm:"not null;default:'Gudang'"`
+	CurrentOPDID    *uuid.UUID   `json:"current_opd_id"`
+	CurrentOPD      *OPD         `json:"current_opd" gorm:"foreignKey:CurrentOPDID"`
+	// CurrentWarehouseID is set while the item is in a warehouse
+	CurrentWarehouseID *uuid.UUID `json:"current_warehouse_id" gorm:"type:uuid;index"`
+	CurrentWarehouse   *Warehouse `json:"current_warehouse,omitempty" gorm:"foreignKey:CurrentWarehouseID"`
+	SpecificLocation   string     `json:"specific_location"`
+	// LocationID is the storage location node holding the item, if any
+	LocationID *uuid.UUID       `json:"location_id" gorm:"type:uuid;index"`
+	Location   *StorageLocation `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+	// CurrentCustodianID is the employee of the holding OPD responsible
+	// for the item, if any
+	CurrentCustodianID *uuid.UUID `json:"current_custodian_id" gorm:"type:uuid;index"`
+	CurrentCustodian   *Custodian `json:"current_custodian,omitempty" gorm:"foreignKey:CurrentCustodianID"`
+	SoftDelete
+	DisposalID   *uuid.UUID    `json:"disposal_id" gorm:"type:uuid"`
+	DisposedAt   *time.Time    `json:"disposed_at"`
+	Transactions []Transaction `json:"transactions,omitempty" gorm:"foreignKey:ItemID"`
+}
+
+// Transaction represents item movements. Rows are never deleted; mistakes
+// are undone by a compensating entry whose ReversalOfID points at the original.
 type Transaction struct {
 	BaseModel
-	ItemID              uuid.UUID            `json:"item_id" gorm:"not null"`
-	Item                Item                 `json:"item,omitempty" gorm:"foreignKey:ItemID"`
-	Direction           TransactionDirection `json:"direction" gorm:"not null"`
-	SourceOPDID         *uuid.UUID           `json:"source_opd_id"`
-	SourceOPD           *OPD                 `json:"source_opd,omitempty" gorm:"foreignKey:SourceOPDID"`
-	TargetOPDID         *uuid.UUID           `json:"target_opd_id"`
-	TargetOPD           *OPD                 `json:"target_opd,omitempty" gorm:"foreignKey:TargetOPDID"`
-	SpecificLocation    string               `json:"specific_location"`
-	Notes               string               `json:"notes"`
-	TransactionDate     time.Time            `json:"transaction_date" gorm:"not null"`
-	ProcessedBy         string               `json:"processed_by"`
+	ItemID      uuid.UUID            `json:"item_id" gorm:"not null"`
+	Item        Item                 `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	Direction   TransactionDirection `json:"direction" gorm:"not null"`
+	SourceOPDID *uuid.UUID           `json:"source_opd_id"`
+	SourceOPD   *OPD                 `json:"source_opd,omitempty" gorm:"foreignKey:SourceOPDID"`
+	TargetOPDID *uuid.UUID           `json:"target_opd_id"`
+	TargetOPD   *OPD                 `json:"target_opd,omitempty" gorm:"foreignKey:TargetOPDID"`
+	// SourceWarehouseID and TargetWarehouseID are set on the warehouse ends
+	// of a movement
+	SourceWarehouseID *uuid.UUID `json:"source_warehouse_id" gorm:"type:uuid;index"`
+	SourceWarehouse   *Warehouse `json:"source_warehouse,omitempty" gorm:"foreignKey:SourceWarehouseID"`
+	TargetWarehouseID *uuid.UUID `json:"target_warehouse_id" gorm:"type:uuid;index"`
+	TargetWarehouse   *Warehouse `json:"target_warehouse,omitempty" gorm:"foreignKey:TargetWarehouseID"`
+	// SourceCustodianID is who was responsible for the item when it left,
+	// TargetCustodianID who is once it arrives
+	SourceCustodianID *uuid.UUID `json:"source_custodian_id" gorm:"type:uuid;index"`
+	SourceCustodian   *Custodian `json:"source_custodian,omitempty" gorm:"foreignKey:SourceCustodianID"`
+	TargetCustodianID *uuid.UUID `json:"target_custodian_id" gorm:"type:uuid;index"`
+	TargetCustodian   *Custodian `json:"target_custodian,omitempty" gorm:"foreignKey:TargetCustodianID"`
+	SpecificLocation  string     `json:"specific_location"`
+	// LocationID is the storage location the item is placed at on arrival;
+	// SourceLocationID the one it left
+	LocationID       *uuid.UUID        `json:"location_id" gorm:"type:uuid;index"`
+	SourceLocationID *uuid.UUID        `json:"source_location_id" gorm:"type:uuid;index"`
+	Notes            string            `json:"notes"`
+	TransactionDate  time.Time         `json:"transaction_date" gorm:"not null"`
+	ProcessedBy      string            `json:"processed_by"`
+	ProcessedByID    *uuid.UUID        `json:"processed_by_id" gorm:"type:uuid"`
+	ReversalOfID     *uuid.UUID        `json:"reversal_of_id" gorm:"type:uuid;uniqueIndex"`
+	BatchID          *uuid.UUID        `json:"batch_id" gorm:"type:uuid;index"`
+	RequestID        *uuid.UUID        `json:"request_id" gorm:"type:uuid;index"`
+	Status           TransactionStatus `json:"status" gorm:"not null;default:'completed';index"`
+	ReceiptDueAt     *time.Time        `json:"receipt_due_at"`
+	ReceiptAt        *time.Time        `json:"receipt_at"`
+	ReceiptBy        string            `json:"receipt_by"`
+	ReceiptByID      *uuid.UUID        `json:"receipt_by_id" gorm:"type:uuid"`
+	ReceiptNotes     string            `json:"receipt_notes"`
+	ReceiptCondition Condition         `json:"receipt_condition"`
+	LoanDueDate      *time.Time        `json:"loan_due_date" gorm:"index"`
+	LoanReturnedAt   *time.Time        `json:"loan_returned_at"`
+	LoanReturnID     *uuid.UUID        `json:"loan_return_id" gorm:"type:uuid"`
+	Reversal         *Transaction      `json:"reversal,omitempty" gorm:"foreignKey:ReversalOfID"`
+	Edits            []TransactionEdit `json:"edits,omitempty" gorm:"foreignKey:TransactionID"`
+	// DeletedAt is only set on transactions deleted before transactions
+	// became immutable. gorm keeps those rows out of every query.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// SiteID returns the warehouse or OPD holding the item, nil while it is in
+// transit or disposed
+func (i *Item) SiteID() *uuid.UUID {
+	switch i.CurrentLocation {
+	case LocationWarehouse:
+		return i.CurrentWarehouseID
+	case LocationOPD:
+		return i.CurrentOPDID
+	}
+	return nil
+}
+
+// Bounced reports whether the movement was turned back before taking effect
+func (t *Transaction) Bounced() bool {
+	return t.Status == TransactionRejected || t.Status == TransactionExpired
+}
+
+// TransactionEdit records a change to a non-location field of a transaction.
+// Transactions are otherwise immutable.
+type TransactionEdit struct {
+	BaseModel
+	TransactionID uuid.UUID  `json:"transaction_id" gorm:"type:uuid;not null;index"`
+	Field         string     `json:"field" gorm:"not null"`
+	OldValue      string     `json:"old_value"`
+	NewValue      string     `json:"new_value"`
+	EditedBy      string     `json:"edited_by"`
+	EditedByID    *uuid.UUID `json:"edited_by_id" gorm:"type:uuid"`
 }
 
 // Request/Response DTOs
@@ -99,21 +274,88 @@ type CreateItemRequest struct {
 	Condition        Condition `json:"condition" binding:"required"`
 	Description      string    `json:"description"`
 	SpecificLocation string    `json:"specific_location"`
+	// WarehouseID is the warehouse a new item enters, the default one when
+	// empty. LocationID places it at a storage location there. Both are
+	// ignored on update; items are moved instead.
+	WarehouseID *uuid.UUID `json:"warehouse_id"`
+	LocationID  *uuid.UUID `json:"location_id"`
 }
 
 type CreateTransactionRequest struct {
-	ItemID           uuid.UUID            `json:"item_id" binding:"required"`
-	Direction        TransactionDirection `json:"direction" binding:"required"`
-	SourceOPDID      *uuid.UUID           `json:"source_opd_id"`
-	TargetOPDID      *uuid.UUID           `json:"target_opd_id"`
-	SpecificLocation string               `json:"specific_location"`
-	Notes            string               `json:"notes"`
-	ProcessedBy      string               `json:"processed_by"`
+	ItemID      uuid.UUID            `json:"item_id" binding:"required"`
+	Direction   TransactionDirection `json:"direction" binding:"required"`
+	SourceOPDID *uuid.UUID           `json:"source_opd_id"`
+	TargetOPDID *uuid.UUID           `json:"target_opd_id"`
+	// SourceWarehouseID defaults to the item's warehouse, TargetWarehouseID
+	// of a return to the default warehouse
+	SourceWarehouseID *uuid.UUID `json:"source_warehouse_id"`
+	TargetWarehouseID *uuid.UUID `json:"target_warehouse_id"`
+	// TargetCustodianID is a custodian of the target OPD made responsible
+	// for the item
+	TargetCustodianID *uuid.UUID `json:"target_custodian_id"`
+	SpecificLocation  string     `json:"specific_location"`
+	// LocationID is a storage location of the destination to place the
+	// item at. It replaces SpecificLocation.
+	LocationID *uuid.UUID `json:"location_id"`
+	Notes      string     `json:"notes"`
+	// RequireReceipt keeps the item in transit until the receiver confirms
+	RequireReceipt bool `json:"require_receipt"`
+	// LoanDueDate lends the item until the given date (YYYY-MM-DD or
+	// RFC 3339). Only items issued from the warehouse can be lent.
+	LoanDueDate string `json:"loan_due_date"`
+	// ProcessedBy is stamped from the authenticated user, never from the body
+	ProcessedBy   string     `json:"-"`
+	ProcessedByID *uuid.UUID `json:"-"`
+}
+
+// ConfirmReceiptRequest accepts an in-transit movement. Condition records
+// a discrepancy found on arrival and updates the item.
+type ConfirmReceiptRequest struct {
+	Condition Condition `json:"condition"`
+	Notes     string    `json:"notes"`
 }
 
+type RejectReceiptRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// UpdateTransactionRequest only carries fields that do not affect item location
+type UpdateTransactionRequest struct {
+	Notes string `json:"notes"`
+	// EditedBy is stamped from the authenticated user, never from the body
+	EditedBy   string     `json:"-"`
+	EditedByID *uuid.UUID `json:"-"`
+}
+
+type ReverseTransactionRequest struct {
+	Reason           string `json:"reason" binding:"required"`
+	SpecificLocation string `json:"specific_location"`
+	// LocationID places the item back at a storage location. By default it
+	// returns to the one it left, if that still exists.
+	LocationID *uuid.UUID `json:"location_id"`
+	// ProcessedBy is stamped from the authenticated user, never from the body
+	ProcessedBy   string     `json:"-"`
+	ProcessedByID *uuid.UUID `json:"-"`
+}
+
+// CreateOPDRequest creates or updates an OPD. ParentID places a new OPD
+// under another; an existing OPD is moved with MoveOPDRequest.
 type CreateOPDRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+}
+
+// MoveOPDRequest puts an OPD and the units under it below another parent,
+// or at the top level when ParentID is empty
+type MoveOPDRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// OPDSearchParams selects the flat list of OPDs or, with Tree, the nested
+// tree with item counts
+type OPDSearchParams struct {
+	Tree bool `form:"tree"`
 }
 
 type CreateCategoryRequest struct {
@@ -122,13 +364,20 @@ type CreateCategoryRequest struct {
 }
 
 type DashboardSummary struct {
-	TotalItems       int64                    `json:"total_items"`
-	ItemsInWarehouse int64                    `json:"items_in_warehouse"`
-	ItemsInOPD       int64                    `json:"items_in_opd"`
-	TotalTransactions int64                   `json:"total_transactions"`
-	ItemsByCondition map[Condition]int64      `json:"items_by_condition"`
-	ItemsByCategory  []CategorySummary        `json:"items_by_category"`
-	ItemsByOPD       []OPDSummary             `json:"items_by_opd"`
+	TotalItems         int64               `json:"total_items"`
+	ItemsInWarehouse   int64               `json:"items_in_warehouse"`
+	ItemsInOPD         int64               `json:"items_in_opd"`
+	ItemsInTransit     int64               `json:"items_in_transit"`
+	ItemsInMaintenance int64               `json:"items_in_maintenance"`
+	ItemsDisposed      int64               `json:"items_disposed"`
+	OpenLoans          int64               `json:"open_loans"`
+	OverdueLoans       int64               `json:"overdue_loans"`
+	OverdueLoansByOPD  []OPDSummary        `json:"overdue_loans_by_opd"`
+	TotalTransactions  int64               `json:"total_transactions"`
+	ItemsByCondition   map[Condition]int64 `json:"items_by_condition"`
+	ItemsByCategory    []CategorySummary   `json:"items_by_category"`
+	ItemsByOPD         []OPDSummary        `json:"items_by_opd"`
+	ItemsByWarehouse   []WarehouseSummary  `json:"items_by_warehouse"`
 }
 
 type CategorySummary struct {
@@ -136,25 +385,76 @@ type CategorySummary struct {
 	Count        int64  `json:"count"`
 }
 
+// OPDSummary counts items of an OPD. Count covers the OPD itself and
+// TotalCount adds the units under it.
 type OPDSummary struct {
-	OPDName string `json:"opd_name"`
-	Count   int64  `json:"count"`
+	OPDID      uuid.UUID  `json:"opd_id"`
+	ParentID   *uuid.UUID `json:"parent_id"`
+	OPDName    string     `json:"opd_name"`
+	Count      int64      `json:"count"`
+	TotalCount int64      `json:"total_count"`
 }
 
 type ItemSearchParams struct {
 	Query      string `form:"q"`
 	CategoryID string `form:"category_id"`
 	OPDID      string `form:"opd_id"`
-	Location   string `form:"location"`
+	// IncludeSubUnits widens OPDID to the units under that OPD
+	IncludeSubUnits bool `form:"include_sub_units"`
+	// WarehouseID matches items currently in that warehouse
+	WarehouseID string `form:"warehouse_id"`
+	// CustodianID matches items the custodian is responsible for
+	CustodianID string `form:"custodian_id"`
+	Location    string `form:"location"`
+	// LocationID matches items anywhere under a storage location
+	LocationID string `form:"location_id"`
 	Condition  string `form:"condition"`
 	Page       int    `form:"page"`
 	Limit      int    `form:"limit"`
 }
 
+type TransactionSearchParams struct {
+	Direction string `form:"direction"`
+	ItemID    string `form:"item_id"`
+	BatchID   string `form:"batch_id"`
+	RequestID string `form:"request_id"`
+	Status    string `form:"status"`
+	OPDID     string `form:"opd_id"`
+	// WarehouseID matches movements into or out of that warehouse
+	WarehouseID string `form:"warehouse_id"`
+	From        string `form:"from"`
+	To          string `form:"to"`
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit"`
+}
+
+// DeleteParams are the options of deleting an OPD or category.
+// ReassignTo moves the items still attached to another OPD or category
+// first.
+type DeleteParams struct {
+	ReassignTo string `form:"reassign_to"`
+}
+
+// BlockingItem is an item that keeps an OPD or category from being deleted
+type BlockingItem struct {
+	ID              uuid.UUID    `json:"id"`
+	SerialNumber    string       `json:"serial_number"`
+	Brand           string       `json:"brand"`
+	Model           string       `json:"model"`
+	CurrentLocation LocationType `json:"current_location"`
+}
+
+// TrashSearchParams filters the deleted rows of an entity
+type TrashSearchParams struct {
+	Query string `form:"q"`
+	Page  int    `form:"page"`
+	Limit int    `form:"limit"`
+}
+
 type PaginatedResponse struct {
 	Data       interface{} `json:"data"`
 	TotalCount int64       `json:"total_count"`
 	Page       int         `json:"page"`
 	Limit      int         `json:"limit"`
 	TotalPages int         `json:"total_pages"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReorganizationKind string
+
+const (
+	ReorganizationMerge ReorganizationKind = "merge"
+	ReorganizationSplit ReorganizationKind = "split"
+)
+
+// OPDReorganization records a restructuring of OPDs: a merge of several
+// OPDs into one, or a split of one OPD into several. Items change hands
+// through OPD → OPD transfer batches, so their history shows the move.
+type OPDReorganization struct {
+	BaseModel
+	Kind          ReorganizationKind `json:"kind" gorm:"not null;index"`
+	DecreeNumber  string             `json:"decree_number"`
+	Notes         string             `json:"notes"`
+	ItemCount     int                `json:"item_count"`
+	EffectiveAt   time.Time          `json:"effective_at" gorm:"not null"`
+	ProcessedBy   string             `json:"processed_by"`
+	ProcessedByID *uuid.UUID         `json:"processed_by_id" gorm:"type:uuid"`
+	Lineage       []OPDLineage       `json:"lineage,omitempty" gorm:"foreignKey:ReorganizationID"`
+}
+
+// OPDLineage links an OPD to one that took over (part of) its items in a
+// reorganization. BatchID is the transfer batch that moved them, empty
+// when there was nothing to move.
+type OPDLineage struct {
+	BaseModel
+	ReorganizationID uuid.UUID          `json:"reorganization_id" gorm:"type:uuid;not null;index"`
+	Reorganization   *OPDReorganization `json:"reorganization,omitempty" gorm:"foreignKey:ReorganizationID"`
+	PredecessorID    uuid.UUID          `json:"predecessor_id" gorm:"type:uuid;not null;index"`
+	Predecessor      *OPD               `json:"predecessor,omitempty" gorm:"foreignKey:PredecessorID"`
+	SuccessorID      uuid.UUID          `json:"successor_id" gorm:"type:uuid;not null;index"`
+	Successor        *OPD               `json:"successor,omitempty" gorm:"foreignKey:SuccessorID"`
+	BatchID          *uuid.UUID         `json:"batch_id" gorm:"type:uuid"`
+	ItemCount        int                `json:"item_count"`
+}
+
+// MergeOPDsRequest moves every item, sub-unit and custodian of the source
+// OPDs to one target and deletes the sources. The target is an existing
+// OPD (TargetOPDID) or a new one (NewOPD).
+type MergeOPDsRequest struct {
+	SourceOPDIDs []uuid.UUID       `json:"source_opd_ids" binding:"required,min=1"`
+	TargetOPDID  *uuid.UUID        `json:"target_opd_id"`
+	NewOPD       *CreateOPDRequest `json:"new_opd"`
+	DecreeNumber string            `json:"decree_number"`
+	Notes        string            `json:"notes"`
+}
+
+// SplitOPDRequest hands the items of one OPD to several others. Items not
+// named in any part stay with the source, which is deleted only when
+// DeleteSource is set and nothing is left.
+type SplitOPDRequest struct {
+	SourceOPDID  uuid.UUID      `json:"source_opd_id" binding:"required"`
+	Parts        []SplitOPDPart `json:"parts" binding:"required,min=1,dive"`
+	DeleteSource bool           `json:"delete_source"`
+	DecreeNumber string         `json:"decree_number"`
+	Notes        string         `json:"notes"`
+}
+
+// SplitOPDPart is one successor of a split: an existing OPD or a new one,
+// with the items it takes over
+type SplitOPDPart struct {
+	OPDID   *uuid.UUID        `json:"opd_id"`
+	NewOPD  *CreateOPDRequest `json:"new_opd"`
+	ItemIDs []uuid.UUID       `json:"item_ids"`
+}
+
+type ReorganizationSearchParams struct {
+	Kind  string `form:"kind"`
+	OPDID string `form:"opd_id"`
+	Page  int    `form:"page"`
+	Limit int    `form:"limit"`
+}
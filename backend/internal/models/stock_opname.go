@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockOpnameStatus string
+
+const (
+	StockOpnameOpen   StockOpnameStatus = "open"
+	StockOpnameClosed StockOpnameStatus = "closed"
+)
+
+type StockOpnameResult string
+
+const (
+	OpnameResultFound            StockOpnameResult = "found"
+	OpnameResultMissing          StockOpnameResult = "missing"
+	OpnameResultUnexpected       StockOpnameResult = "unexpected"
+	OpnameResultConditionChanged StockOpnameResult = "condition_changed"
+)
+
+// StockOpname is a physical inventory count of one warehouse or one OPD.
+// Opening it snapshots the items expected at the location as entries;
+// scans mark entries as seen or add unexpected ones.
+type StockOpname struct {
+	BaseModel
+	Location    LocationType       `json:"location" gorm:"not null"`
+	OPDID       *uuid.UUID         `json:"opd_id" gorm:"type:uuid"`
+	OPD         *OPD               `json:"opd,omitempty" gorm:"foreignKey:OPDID"`
+	WarehouseID *uuid.UUID         `json:"warehouse_id" gorm:"type:uuid"`
+	Warehouse   *Warehouse         `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+	Status      StockOpnameStatus  `json:"status" gorm:"not null;default:'open';index"`
+	Notes       string             `json:"notes"`
+	OpenedBy    string             `json:"opened_by"`
+	OpenedByID  *uuid.UUID         `json:"opened_by_id" gorm:"type:uuid"`
+	ClosedBy    string             `json:"closed_by"`
+	ClosedByID  *uuid.UUID         `json:"closed_by_id" gorm:"type:uuid"`
+	ClosedAt    *time.Time         `json:"closed_at"`
+	CorrectedBy string             `json:"corrected_by"`
+	CorrectedAt *time.Time         `json:"corrected_at"`
+	Entries     []StockOpnameEntry `json:"entries,omitempty" gorm:"foreignKey:OpnameID"`
+}
+
+// SiteID returns the warehouse or OPD being counted
+func (o *StockOpname) SiteID() *uuid.UUID {
+	if o.Location == LocationWarehouse {
+		return o.WarehouseID
+	}
+	return o.OPDID
+}
+
+// StockOpnameEntry is one serial number in a count. Result is filled in
+// when the session is closed.
+type StockOpnameEntry struct {
+	BaseModel
+	OpnameID          uuid.UUID         `json:"opname_id" gorm:"type:uuid;not null;uniqueIndex:idx_opname_entry_serial"`
+	SerialNumber      string            `json:"serial_number" gorm:"not null;uniqueIndex:idx_opname_entry_serial"`
+	ItemID            *uuid.UUID        `json:"item_id" gorm:"type:uuid"`
+	Expected          bool              `json:"expected"`
+	ExpectedCondition Condition         `json:"expected_condition"`
+	Scanned           bool              `json:"scanned"`
+	ScannedCondition  Condition         `json:"scanned_condition"`
+	ScannedAt         *time.Time        `json:"scanned_at"`
+	ScannedBy         string            `json:"scanned_by"`
+	Notes             string            `json:"notes"`
+	Result            StockOpnameResult `json:"result"`
+}
+
+// EvaluateResult classifies the entry from what was expected and scanned
+func (e *StockOpnameEntry) EvaluateResult() StockOpnameResult {
+	switch {
+	case e.Expected && !e.Scanned:
+		return OpnameResultMissing
+	case !e.Expected:
+		return OpnameResultUnexpected
+	case e.ScannedCondition != e.ExpectedCondition:
+		return OpnameResultConditionChanged
+	default:
+		return OpnameResultFound
+	}
+}
+
+type OpenStockOpnameRequest struct {
+	Location LocationType `json:"location" binding:"required"`
+	OPDID    *uuid.UUID   `json:"opd_id"`
+	// WarehouseID picks the warehouse to count, the default one when empty
+	WarehouseID *uuid.UUID `json:"warehouse_id"`
+	Notes       string     `json:"notes"`
+}
+
+type ScanStockOpnameRequest struct {
+	SerialNumber string `json:"serial_number" binding:"required"`
+	// Condition is what the counter observed, empty keeps the recorded one
+	Condition Condition `json:"condition"`
+	Notes     string    `json:"notes"`
+}
+
+// PostStockOpnameCorrectionsRequest chooses which variances of a closed
+// session are written back to the items
+type PostStockOpnameCorrectionsRequest struct {
+	ApplyConditions    bool   `json:"apply_conditions"`
+	RelocateUnexpected bool   `json:"relocate_unexpected"`
+	MarkMissingLost    bool   `json:"mark_missing_lost"`
+	SpecificLocation   string `json:"specific_location"`
+}
+
+type StockOpnameSearchParams struct {
+	Status      string `form:"status"`
+	Location    string `form:"location"`
+	OPDID       string `form:"opd_id"`
+	WarehouseID string `form:"warehouse_id"`
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit"`
+}
+
+// StockOpnameReport is the variance report of a session. For an open
+// session it previews what closing it now would produce.
+type StockOpnameReport struct {
+	Opname           *StockOpname       `json:"opname"`
+	ExpectedCount    int                `json:"expected_count"`
+	ScannedCount     int                `json:"scanned_count"`
+	Found            []StockOpnameEntry `json:"found"`
+	Missing          []StockOpnameEntry `json:"missing"`
+	Unexpected       []StockOpnameEntry `json:"unexpected"`
+	ConditionChanged []StockOpnameEntry `json:"condition_changed"`
+}
+
+// StockOpnameCorrections summarizes the changes posted from a session
+type StockOpnameCorrections struct {
+	ConditionsUpdated int         `json:"conditions_updated"`
+	ItemsRelocated    int         `json:"items_relocated"`
+	ItemsMarkedLost   int         `json:"items_marked_lost"`
+	TransactionIDs    []uuid.UUID `json:"transaction_ids"`
+	Skipped           []string    `json:"skipped"`
+}
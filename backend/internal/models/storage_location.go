@@ -0,0 +1,144 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StorageLocationKind string
+
+const (
+	StorageBuilding StorageLocationKind = "building"
+	StorageRoom     StorageLocationKind = "room"
+	StorageRack     StorageLocationKind = "rack"
+	StorageBin      StorageLocationKind = "bin"
+)
+
+// Level orders kinds from building (1) down to bin (4). It is 0 for an
+// unknown kind.
+func (k StorageLocationKind) Level() int {
+	switch k {
+	case StorageBuilding:
+		return 1
+	case StorageRoom:
+		return 2
+	case StorageRack:
+		return 3
+	case StorageBin:
+		return 4
+	}
+	return 0
+}
+
+func (k StorageLocationKind) IsValid() bool {
+	return k.Level() > 0
+}
+
+// StorageLocation is a node of the location tree of a site: the warehouse
+// WarehouseID or the OPD OPDID, exactly one of which is set. Path lists the
+// node IDs from the root down to the node, so a subtree is a single prefix
+// match. FullName joins the names along the same path and is what items
+// placed here carry as their specific location.
+type StorageLocation struct {
+	BaseModel
+	ParentID    *uuid.UUID          `json:"parent_id" gorm:"type:uuid;index"`
+	OPDID       *uuid.UUID          `json:"opd_id" gorm:"type:uuid;index"`
+	OPD         *OPD                `json:"opd,omitempty" gorm:"foreignKey:OPDID"`
+	WarehouseID *uuid.UUID          `json:"warehouse_id" gorm:"type:uuid;index"`
+	Warehouse   *Warehouse          `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+	Kind        StorageLocationKind `json:"kind" gorm:"not null"`
+	Name        string              `json:"name" gorm:"not null"`
+	Code        string              `json:"code"`
+	Description string              `json:"description"`
+	Path        string              `json:"path" gorm:"not null;index"`
+	FullName    string              `json:"full_name" gorm:"not null"`
+}
+
+// IsAt reports whether the node belongs to the given site, a warehouse or
+// an OPD identified by siteID
+func (l *StorageLocation) IsAt(location LocationType, siteID *uuid.UUID) bool {
+	if siteID == nil {
+		return false
+	}
+	switch location {
+	case LocationWarehouse:
+		return l.WarehouseID != nil && *l.WarehouseID == *siteID
+	case LocationOPD:
+		return l.OPDID != nil && *l.OPDID == *siteID
+	}
+	return false
+}
+
+// Site returns the kind and ID of the site the node belongs to
+func (l *StorageLocation) Site() (LocationType, uuid.UUID) {
+	if l.WarehouseID != nil {
+		return LocationWarehouse, *l.WarehouseID
+	}
+	return LocationOPD, *l.OPDID
+}
+
+// StorageLocationNode is a storage location in a tree response. ItemCount
+// counts the items placed at the node itself, TotalItems those anywhere
+// under it.
+type StorageLocationNode struct {
+	StorageLocation
+	ItemCount  int64                  `json:"item_count"`
+	TotalItems int64                  `json:"total_items"`
+	Children   []*StorageLocationNode `json:"children"`
+}
+
+// ItemMove records an item moved between storage locations of the site
+// holding it. Moves are not transactions: the item stays with the same OPD
+// or in the same warehouse.
+type ItemMove struct {
+	BaseModel
+	ItemID               uuid.UUID        `json:"item_id" gorm:"type:uuid;not null;index"`
+	Item                 *Item            `json:"item,omitempty" gorm:"foreignKey:ItemID"`
+	OPDID                *uuid.UUID       `json:"opd_id" gorm:"type:uuid;index"`
+	WarehouseID          *uuid.UUID       `json:"warehouse_id" gorm:"type:uuid;index"`
+	FromLocationID       *uuid.UUID       `json:"from_location_id" gorm:"type:uuid;index"`
+	FromLocation         *StorageLocation `json:"from_location,omitempty" gorm:"foreignKey:FromLocationID"`
+	FromSpecificLocation string           `json:"from_specific_location"`
+	ToLocationID         uuid.UUID        `json:"to_location_id" gorm:"type:uuid;not null;index"`
+	ToLocation           *StorageLocation `json:"to_location,omitempty" gorm:"foreignKey:ToLocationID"`
+	Notes                string           `json:"notes"`
+	MovedAt              time.Time        `json:"moved_at" gorm:"not null"`
+	MovedBy              string           `json:"moved_by"`
+	MovedByID            *uuid.UUID       `json:"moved_by_id" gorm:"type:uuid"`
+}
+
+// CreateStorageLocationRequest adds a node. A child belongs to the site of
+// its parent; a root node names its site with either WarehouseID or OPDID.
+type CreateStorageLocationRequest struct {
+	ParentID    *uuid.UUID          `json:"parent_id"`
+	OPDID       *uuid.UUID          `json:"opd_id"`
+	WarehouseID *uuid.UUID          `json:"warehouse_id"`
+	Kind        StorageLocationKind `json:"kind" binding:"required"`
+	Name        string              `json:"name" binding:"required"`
+	Code        string              `json:"code"`
+	Description string              `json:"description"`
+}
+
+// UpdateStorageLocationRequest renames a node. Nodes are not re-parented;
+// move their items instead.
+type UpdateStorageLocationRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// MoveItemsRequest moves items to another storage location of the site that
+// holds them
+type MoveItemsRequest struct {
+	ItemIDs    []uuid.UUID `json:"item_ids" binding:"required,min=1"`
+	LocationID uuid.UUID   `json:"location_id" binding:"required"`
+	Notes      string      `json:"notes"`
+}
+
+// StorageLocationSearchParams picks the site of a tree: an OPD, a
+// warehouse, or the default warehouse when both are empty
+type StorageLocationSearchParams struct {
+	OPDID       string `form:"opd_id"`
+	WarehouseID string `form:"warehouse_id"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransferBatch groups the movements of one delivery. Every item gets its
+// own Transaction pointing back at the batch.
+type TransferBatch struct {
+	BaseModel
+	Direction         TransactionDirection `json:"direction" gorm:"not null"`
+	SourceOPDID       *uuid.UUID           `json:"source_opd_id" gorm:"type:uuid"`
+	SourceOPD         *OPD                 `json:"source_opd,omitempty" gorm:"foreignKey:SourceOPDID"`
+	TargetOPDID       *uuid.UUID           `json:"target_opd_id" gorm:"type:uuid"`
+	TargetOPD         *OPD                 `json:"target_opd,omitempty" gorm:"foreignKey:TargetOPDID"`
+	SourceWarehouseID *uuid.UUID           `json:"source_warehouse_id" gorm:"type:uuid"`
+	SourceWarehouse   *Warehouse           `json:"source_warehouse,omitempty" gorm:"foreignKey:SourceWarehouseID"`
+	TargetWarehouseID *uuid.UUID           `json:"target_warehouse_id" gorm:"type:uuid"`
+	TargetWarehouse   *Warehouse           `json:"target_warehouse,omitempty" gorm:"foreignKey:TargetWarehouseID"`
+	TargetCustodianID *uuid.UUID           `json:"target_custodian_id" gorm:"type:uuid"`
+	TargetCustodian   *Custodian           `json:"target_custodian,omitempty" gorm:"foreignKey:TargetCustodianID"`
+	SpecificLocation  string               `json:"specific_location"`
+	LocationID        *uuid.UUID           `json:"location_id" gorm:"type:uuid"`
+	Notes             string               `json:"notes"`
+	BatchDate         time.Time            `json:"batch_date" gorm:"not null"`
+	ItemCount         int                  `json:"item_count" gorm:"not null"`
+	RequestID         *uuid.UUID           `json:"request_id" gorm:"type:uuid;index"`
+	ProcessedBy       string               `json:"processed_by"`
+	ProcessedByID     *uuid.UUID           `json:"processed_by_id" gorm:"type:uuid"`
+	Transactions      []Transaction        `json:"transactions,omitempty" gorm:"foreignKey:BatchID"`
+}
+
+type CreateTransferBatchRequest struct {
+	ItemIDs     []uuid.UUID          `json:"item_ids" binding:"required,min=1"`
+	Direction   TransactionDirection `json:"direction" binding:"required"`
+	SourceOPDID *uuid.UUID           `json:"source_opd_id"`
+	TargetOPDID *uuid.UUID           `json:"target_opd_id"`
+	// SourceWarehouseID, when given, must be the warehouse of every item
+	SourceWarehouseID *uuid.UUID `json:"source_warehouse_id"`
+	TargetWarehouseID *uuid.UUID `json:"target_warehouse_id"`
+	// TargetCustodianID makes a custodian of the target OPD responsible
+	// for every item
+	TargetCustodianID *uuid.UUID `json:"target_custodian_id"`
+	SpecificLocation  string     `json:"specific_location"`
+	// LocationID is a storage location of the destination to place every
+	// item at
+	LocationID *uuid.UUID `json:"location_id"`
+	Notes      string     `json:"notes"`
+	// RequireReceipt keeps the items in transit until the receiver confirms
+	RequireReceipt bool `json:"require_receipt"`
+	// LoanDueDate lends every item of the batch until the given date
+	LoanDueDate string `json:"loan_due_date"`
+	// RequestID links a delivery to the item request it fulfils
+	RequestID *uuid.UUID `json:"-"`
+	// ProcessedBy is stamped from the authenticated user, never from the body
+	ProcessedBy   string     `json:"-"`
+	ProcessedByID *uuid.UUID `json:"-"`
+}
+
+type TransferBatchSearchParams struct {
+	Direction string `form:"direction"`
+	OPDID     string `form:"opd_id"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+	Page      int    `form:"page"`
+	Limit     int    `form:"limit"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// DefaultWarehouseName names the warehouse created for data recorded before
+// there were several
+const DefaultWarehouseName = "Gudang Utama"
+
+// Warehouse is a store holding items that are not issued to an OPD. Exactly
+// one warehouse is the default: new and imported items enter it, and
+// returns that name no warehouse go there.
+type Warehouse struct {
+	BaseModel
+	Name      string `json:"name" gorm:"not null;uniqueIndex:idx_warehouses_active_name,where:is_active = true"`
+	Address   string `json:"address"`
+	IsDefault bool   `json:"is_default" gorm:"not null;default:false"`
+	SoftDelete
+}
+
+// CreateWarehouseRequest creates or updates a warehouse. IsDefault moves
+// the default to this warehouse; the default cannot be unset directly.
+type CreateWarehouseRequest struct {
+	Name      string `json:"name" binding:"required"`
+	Address   string `json:"address"`
+	IsDefault bool   `json:"is_default"`
+}
+
+type WarehouseSummary struct {
+	WarehouseID   uuid.UUID `json:"warehouse_id"`
+	WarehouseName string    `json:"warehouse_name"`
+	Count         int64     `json:"count"`
+}
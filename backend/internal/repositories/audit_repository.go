@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"time"
+
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) CreateAuditLog(log *models.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+// GetAuditLogs lists audit entries, newest first. from and to are inclusive
+// bounds and may be zero.
+func (r *AuditRepository) GetAuditLogs(params *models.AuditSearchParams, from, to time.Time) ([]models.AuditLog, int64, error) {
+	var logs []models.AuditLog
+	var total int64
+
+	query := r.db.Model(&models.AuditLog{})
+
+	if params.EntityType != "" {
+		query = query.Where("entity_type = ?", params.EntityType)
+	}
+
+	if params.EntityID != "" {
+		if entityUUID, err := uuid.Parse(params.EntityID); err == nil {
+			query = query.Where("entity_id = ?", entityUUID)
+		}
+	}
+
+	if params.ActorID != "" {
+		if actorUUID, err := uuid.Parse(params.ActorID); err == nil {
+			query = query.Where("actor_id = ?", actorUUID)
+		}
+	}
+
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+
+	if !to.IsZero() {
+		query = query.Where("created_at <= ?", to)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Offset(offset).Limit(params.Limit).Order("created_at DESC").Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
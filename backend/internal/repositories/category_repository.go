@@ -3,6 +3,7 @@ package repositories
 import (
 	"warehouse-system/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -38,6 +39,42 @@ func (r *CategoryRepository) UpdateCategory(id string, category *models.Category
 	return r.db.Where("id = ?", id).Updates(category).Error
 }
 
-func (r *CategoryRepository) DeleteCategory(id string) error {
-	return r.db.Model(&models.Category{}).Where("id = ?", id).Update("is_active", false).Error
+// UpdateDeletion stores the SoftDelete fields of a category
+func (r *CategoryRepository) UpdateDeletion(category *models.Category) error {
+	return r.db.Model(category).Select(softDeleteColumns).Updates(category).Error
+}
+
+// GetDeletedCategories lists categories in the trash, most recently deleted first
+func (r *CategoryRepository) GetDeletedCategories() ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.Where("is_active = ?", false).Order("deleted_at DESC NULLS LAST").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// FindActiveByName returns the active category with the given name, if any
+func (r *CategoryRepository) FindActiveByName(name string) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.First(&category, "name = ? AND is_active = ?", name, true).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// FindBlockingItems returns up to limit active items of the category and
+// how many there are in all
+func (r *CategoryRepository) FindBlockingItems(id uuid.UUID, limit int) ([]models.BlockingItem, int64, error) {
+	query := r.db.Model(&models.Item{}).Where("is_active = ? AND category_id = ?", true, id)
+	return findBlockingItems(query, limit)
+}
+
+// IsReferenced reports whether any other record still points at the category
+func (r *CategoryRepository) IsReferenced(id uuid.UUID) (bool, error) {
+	return isReferenced(r.db, categoryReferences, id)
+}
+
+// Purge permanently removes a category
+func (r *CategoryRepository) Purge(id uuid.UUID) error {
+	return r.db.Delete(&models.Category{}, "id = ?", id).Error
 }
\ No newline at end of file
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CustodianRepository struct {
+	db *gorm.DB
+}
+
+func NewCustodianRepository(db *gorm.DB) *CustodianRepository {
+	return &CustodianRepository{db: db}
+}
+
+// GetCustodians lists active custodians by name
+func (r *CustodianRepository) GetCustodians(scope models.AccessScope, params *models.CustodianSearchParams) ([]models.Custodian, int64, error) {
+	var custodians []models.Custodian
+	var total int64
+
+	query := r.db.Model(&models.Custodian{}).Where("is_active = ?", true)
+	query = scopeCustodians(query, scope)
+
+	if params.Query != "" {
+		query = query.Where("name ILIKE ? OR nip ILIKE ? OR position ILIKE ?",
+			"%"+params.Query+"%", "%"+params.Query+"%", "%"+params.Query+"%")
+	}
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("opd_id = ?", opdUUID)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("OPD").Offset(offset).Limit(params.Limit).Order("name").Find(&custodians).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return custodians, total, nil
+}
+
+func (r *CustodianRepository) CreateCustodian(custodian *models.Custodian) error {
+	return r.db.Omit(clause.Associations).Create(custodian).Error
+}
+
+func (r *CustodianRepository) GetCustodian(id string) (*models.Custodian, error) {
+	var custodian models.Custodian
+	if err := r.db.Preload("OPD").First(&custodian, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &custodian, nil
+}
+
+func (r *CustodianRepository) UpdateCustodian(custodian *models.Custodian) error {
+	return r.db.Omit(clause.Associations).Save(custodian).Error
+}
+
+// FindActiveByNIP returns the active custodian with the given NIP, if any
+func (r *CustodianRepository) FindActiveByNIP(nip string) (*models.Custodian, error) {
+	var custodian models.Custodian
+	if err := r.db.First(&custodian, "nip = ? AND is_active = ?", nip, true).Error; err != nil {
+		return nil, err
+	}
+	return &custodian, nil
+}
+
+// FindBlockingItems returns up to limit active items the custodian is
+// responsible for or is to receive, and how many there are in all
+func (r *CustodianRepository) FindBlockingItems(id uuid.UUID, limit int) ([]models.BlockingItem, int64, error) {
+	inTransit := r.db.Model(&models.Transaction{}).Select("item_id").
+		Where("status = ? AND target_custodian_id = ?", models.TransactionInTransit, id)
+	query := r.db.Model(&models.Item{}).
+		Where("is_active = ? AND (current_custodian_id = ? OR id IN (?))", true, id, inTransit)
+	return findBlockingItems(query, limit)
+}
+
+// ReassignOPD moves the active custodians of one OPD to another
+func (r *CustodianRepository) ReassignOPD(fromOPDID, toOPDID uuid.UUID) error {
+	return r.db.Model(&models.Custodian{}).
+		Where("opd_id = ? AND is_active = ?", fromOPDID, true).
+		Update("opd_id", toOPDID).Error
+}
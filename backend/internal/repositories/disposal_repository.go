@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DisposalRepository struct {
+	db *gorm.DB
+}
+
+func NewDisposalRepository(db *gorm.DB) *DisposalRepository {
+	return &DisposalRepository{db: db}
+}
+
+func (r *DisposalRepository) GetDisposals(params *models.DisposalSearchParams) ([]models.Disposal, int64, error) {
+	var disposals []models.Disposal
+	var total int64
+
+	query := r.db.Model(&models.Disposal{})
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if params.Reason != "" {
+		query = query.Where("reason = ?", params.Reason)
+	}
+
+	if params.ItemID != "" {
+		if itemUUID, err := uuid.Parse(params.ItemID); err == nil {
+			query = query.Where("id IN (?)", r.db.Model(&models.DisposalItem{}).Select("disposal_id").Where("item_id = ?", itemUUID))
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Offset(offset).Limit(params.Limit).Order("created_at DESC").Find(&disposals).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return disposals, total, nil
+}
+
+// CreateDisposal inserts the proposal with its items
+func (r *DisposalRepository) CreateDisposal(disposal *models.Disposal) error {
+	return r.db.Create(disposal).Error
+}
+
+func (r *DisposalRepository) GetDisposal(id string) (*models.Disposal, error) {
+	var disposal models.Disposal
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("serial_number") }).
+		Preload("Items.Item").
+		Preload("Items.Item.Category").
+		Preload("Approvals", func(db *gorm.DB) *gorm.DB { return db.Order("step") }).
+		First(&disposal, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &disposal, nil
+}
+
+// GetDisposalForUpdate locks a proposal and loads its items and approvals,
+// so reviews and execution of it are serialized
+func (r *DisposalRepository) GetDisposalForUpdate(id string) (*models.Disposal, error) {
+	var disposal models.Disposal
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&disposal, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("disposal_id = ?", disposal.ID).Find(&disposal.Items).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("disposal_id = ?", disposal.ID).Order("step").Find(&disposal.Approvals).Error; err != nil {
+		return nil, err
+	}
+	return &disposal, nil
+}
+
+func (r *DisposalRepository) UpdateDisposal(disposal *models.Disposal) error {
+	return r.db.Omit(clause.Associations).Save(disposal).Error
+}
+
+func (r *DisposalRepository) CreateApproval(approval *models.DisposalApproval) error {
+	return r.db.Create(approval).Error
+}
+
+// FindPendingItems returns the serial numbers of items that are already on
+// a proposal that has not been rejected or executed
+func (r *DisposalRepository) FindPendingItems(itemIDs []uuid.UUID) ([]string, error) {
+	var serialNumbers []string
+	err := r.db.Model(&models.DisposalItem{}).
+		Joins("JOIN disposals ON disposals.id = disposal_items.disposal_id").
+		Where("disposal_items.item_id IN ? AND disposals.status IN ?", itemIDs,
+			[]models.DisposalStatus{models.DisposalProposed, models.DisposalApproved}).
+		Pluck("disposal_items.serial_number", &serialNumbers).Error
+	return serialNumbers, err
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"time"
+
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HandoverRepository struct {
+	db *gorm.DB
+}
+
+func NewHandoverRepository(db *gorm.DB) *HandoverRepository {
+	return &HandoverRepository{db: db}
+}
+
+// NextSequence issues the next document number of a kind for a year. The
+// counter row stays locked until the surrounding transaction ends, so
+// concurrent documents get consecutive numbers and a rollback leaves no gap.
+func (r *HandoverRepository) NextSequence(kind string, year int) (int, error) {
+	var next int
+	err := r.db.Raw(`INSERT INTO document_sequences (kind, year, last_number) VALUES (?, ?, 1)
+		ON CONFLICT (kind, year) DO UPDATE SET last_number = document_sequences.last_number + 1
+		RETURNING last_number`, kind, year).Scan(&next).Error
+	return next, err
+}
+
+func (r *HandoverRepository) CreateHandoverDocument(document *models.HandoverDocument) error {
+	return r.db.Create(document).Error
+}
+
+func (r *HandoverRepository) GetHandoverDocument(id string) (*models.HandoverDocument, error) {
+	var document models.HandoverDocument
+	err := r.db.Preload("SourceOPD").Preload("TargetOPD").Preload("SourceWarehouse").Preload("TargetWarehouse").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("serial_number") }).
+		First(&document, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// GetHandoverDocuments lists documents, newest first. from and to are
+// inclusive bounds on the handover date and may be zero.
+func (r *HandoverRepository) GetHandoverDocuments(scope models.AccessScope, params *models.HandoverSearchParams, from, to time.Time) ([]models.HandoverDocument, int64, error) {
+	var documents []models.HandoverDocument
+	var total int64
+
+	query := scopeHandovers(r.db.Model(&models.HandoverDocument{}), scope)
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("(source_opd_id = ? OR target_opd_id = ?)", opdUUID, opdUUID)
+		}
+	}
+
+	if !from.IsZero() {
+		query = query.Where("handover_date >= ?", from)
+	}
+
+	if !to.IsZero() {
+		query = query.Where("handover_date <= ?", to)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("SourceOPD").Preload("TargetOPD").Preload("SourceWarehouse").Preload("TargetWarehouse").
+		Offset(offset).Limit(params.Limit).Order("handover_date DESC, sequence DESC").
+		Find(&documents).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return documents, total, nil
+}
+
+// GetDocumentedTransactionIDs returns which of the given transactions
+// already appear on a document
+func (r *HandoverRepository) GetDocumentedTransactionIDs(transactionIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.HandoverDocumentLine{}).
+		Where("transaction_id IN ?", transactionIDs).
+		Pluck("transaction_id", &ids).Error
+	return ids, err
+}
@@ -1,20 +1,29 @@
 package repositories
 
 import (
+	"time"
 	"warehouse-system/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ItemRepository interface {
-	GetAll(params *models.ItemSearchParams) ([]models.Item, int64, error)
+	GetAll(scope models.AccessScope, params *models.ItemSearchParams) ([]models.Item, int64, error)
+	StreamAll(scope models.AccessScope, params *models.ItemSearchParams, fn func(items []models.Item) error) error
 	GetByID(id uuid.UUID) (*models.Item, error)
+	GetForUpdate(id uuid.UUID) (*models.Item, error)
 	GetBySerialNumber(serialNumber string) (*models.Item, error)
+	FindBySerialNumber(serialNumber string) (*models.Item, error)
+	FindExistingSerialNumbers(serialNumbers []string) ([]string, error)
 	Create(item *models.Item) error
 	Update(item *models.Item) error
-	Delete(id uuid.UUID) error
-	GetSummary() (*models.DashboardSummary, error)
+	GetDeleted(params *models.TrashSearchParams) ([]models.Item, int64, error)
+	GetDeletedForUpdate(id uuid.UUID) (*models.Item, error)
+	IsReferenced(id uuid.UUID) (bool, error)
+	Purge(id uuid.UUID) error
+	GetSummary(scope models.AccessScope) (*models.DashboardSummary, error)
 }
 
 type itemRepository struct {
@@ -25,14 +34,75 @@ func NewItemRepository(db *gorm.DB) ItemRepository {
 	return &itemRepository{db: db}
 }
 
-func (r *itemRepository) GetAll(params *models.ItemSearchParams) ([]models.Item, int64, error) {
+// exportBatchSize is how many rows StreamAll and StreamTransactions load per query
+const exportBatchSize = 500
+
+func (r *itemRepository) GetAll(scope models.AccessScope, params *models.ItemSearchParams) ([]models.Item, int64, error) {
 	var items []models.Item
 	var total int64
 
-	query := r.db.Model(&models.Item{}).
+	query := r.filter(scope, params).
 		Preload("Category").
 		Preload("CurrentOPD").
-		Where("is_active = ?", true)
+		Preload("CurrentWarehouse").
+		Preload("CurrentCustodian").
+		Preload("Location")
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	if params.Limit == 0 {
+		params.Limit = 20
+	}
+	if params.Page < 1 {
+		params.Page = 1
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Offset(offset).Limit(params.Limit).Order("updated_at DESC").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// StreamAll passes every item matching the filters to fn in batches ordered
+// by serial number, ignoring pagination
+func (r *itemRepository) StreamAll(scope models.AccessScope, params *models.ItemSearchParams, fn func(items []models.Item) error) error {
+	lastSerial := ""
+	for {
+		var items []models.Item
+		query := r.filter(scope, params).
+			Preload("Category").
+			Preload("CurrentOPD").
+			Preload("CurrentWarehouse").
+			Preload("CurrentCustodian").
+			Where("serial_number > ?", lastSerial).
+			Order("serial_number").
+			Limit(exportBatchSize)
+		if err := query.Find(&items).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+		if len(items) < exportBatchSize {
+			return nil
+		}
+		lastSerial = items[len(items)-1].SerialNumber
+	}
+}
+
+// filter builds the items query shared by listing and export
+func (r *itemRepository) filter(scope models.AccessScope, params *models.ItemSearchParams) *gorm.DB {
+	query := r.db.Model(&models.Item{}).Where("is_active = ?", true)
+	query = scopeItems(query, scope)
 
 	// Apply filters
 	if params.Query != "" {
@@ -50,43 +120,56 @@ func (r *itemRepository) GetAll(params *models.ItemSearchParams) ([]models.Item,
 
 	if params.OPDID != "" {
 		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
-			query = query.Where("current_opd_id = ?", opdUUID)
+			if params.IncludeSubUnits {
+				query = query.Where(
+					"current_opd_id IN (SELECT d.id FROM opds d JOIN opds n ON d.path LIKE n.path || '%' WHERE n.id = ?)",
+					opdUUID,
+				)
+			} else {
+				query = query.Where("current_opd_id = ?", opdUUID)
+			}
 		}
 	}
 
-	if params.Location != "" {
-		query = query.Where("current_location = ?", params.Location)
+	if params.WarehouseID != "" {
+		if warehouseUUID, err := uuid.Parse(params.WarehouseID); err == nil {
+			query = query.Where("current_location = ? AND current_warehouse_id = ?", models.LocationWarehouse, warehouseUUID)
+		}
 	}
 
-	if params.Condition != "" {
-		query = query.Where("condition = ?", params.Condition)
+	if params.CustodianID != "" {
+		if custodianUUID, err := uuid.Parse(params.CustodianID); err == nil {
+			query = query.Where("current_custodian_id = ?", custodianUUID)
+		}
 	}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	if params.Location != "" {
+		query = query.Where("current_location = ?", params.Location)
 	}
 
-	// Apply pagination
-	if params.Limit == 0 {
-		params.Limit = 20
-	}
-	if params.Page < 1 {
-		params.Page = 1
+	if params.LocationID != "" {
+		if locationUUID, err := uuid.Parse(params.LocationID); err == nil {
+			query = query.Where(
+				"location_id IN (SELECT d.id FROM storage_locations d JOIN storage_locations n ON d.path LIKE n.path || '%' WHERE n.id = ?)",
+				locationUUID,
+			)
+		}
 	}
 
-	offset := (params.Page - 1) * params.Limit
-	if err := query.Offset(offset).Limit(params.Limit).Order("updated_at DESC").Find(&items).Error; err != nil {
-		return nil, 0, err
+	if params.Condition != "" {
+		query = query.Where("condition = ?", params.Condition)
 	}
 
-	return items, total, nil
+	return query
 }
 
 func (r *itemRepository) GetByID(id uuid.UUID) (*models.Item, error) {
 	var item models.Item
 	err := r.db.Preload("Category").
 		Preload("CurrentOPD").
+		Preload("CurrentWarehouse").
+		Preload("CurrentCustodian").
+		Preload("Location").
 		Preload("Transactions").
 		Preload("Transactions.SourceOPD").
 		Preload("Transactions.TargetOPD").
@@ -97,6 +180,18 @@ func (r *itemRepository) GetByID(id uuid.UUID) (*models.Item, error) {
 	return &item, nil
 }
 
+// GetForUpdate loads an active item and locks its row until the surrounding
+// database transaction ends
+func (r *itemRepository) GetForUpdate(id uuid.UUID) (*models.Item, error) {
+	var item models.Item
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&item, "id = ? AND is_active = ?", id, true).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
 func (r *itemRepository) GetBySerialNumber(serialNumber string) (*models.Item, error) {
 	var item models.Item
 	err := r.db.Preload("Category").
@@ -108,45 +203,155 @@ func (r *itemRepository) GetBySerialNumber(serialNumber string) (*models.Item, e
 	return &item, nil
 }
 
+// FindBySerialNumber looks up an item by serial number regardless of whether
+// it is still active, since the unique index covers deactivated rows as well
+func (r *itemRepository) FindBySerialNumber(serialNumber string) (*models.Item, error) {
+	var item models.Item
+	if err := r.db.First(&item, "serial_number = ?", serialNumber).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindExistingSerialNumbers returns which of the given serial numbers are
+// already taken, by active or deactivated items
+func (r *itemRepository) FindExistingSerialNumbers(serialNumbers []string) ([]string, error) {
+	const chunkSize = 1000
+
+	var existing []string
+	for start := 0; start < len(serialNumbers); start += chunkSize {
+		end := start + chunkSize
+		if end > len(serialNumbers) {
+			end = len(serialNumbers)
+		}
+
+		var chunk []string
+		if err := r.db.Model(&models.Item{}).
+			Where("serial_number IN ?", serialNumbers[start:end]).
+			Pluck("serial_number", &chunk).Error; err != nil {
+			return nil, err
+		}
+		existing = append(existing, chunk...)
+	}
+	return existing, nil
+}
+
 func (r *itemRepository) Create(item *models.Item) error {
 	return r.db.Create(item).Error
 }
 
 func (r *itemRepository) Update(item *models.Item) error {
-	return r.db.Save(item).Error
+	return r.db.Omit(clause.Associations).Save(item).Error
+}
+
+// GetDeleted lists items in the trash, most recently deleted first
+func (r *itemRepository) GetDeleted(params *models.TrashSearchParams) ([]models.Item, int64, error) {
+	var items []models.Item
+	var total int64
+
+	query := r.db.Model(&models.Item{}).Where("is_active = ?", false)
+	if params.Query != "" {
+		query = query.Where(
+			"serial_number ILIKE ? OR brand ILIKE ? OR model ILIKE ?",
+			"%"+params.Query+"%", "%"+params.Query+"%", "%"+params.Query+"%",
+		)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("Category").Preload("CurrentOPD").
+		Offset(offset).Limit(params.Limit).Order("deleted_at DESC NULLS LAST").
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// GetDeletedForUpdate loads an item in the trash and locks its row
+func (r *itemRepository) GetDeletedForUpdate(id uuid.UUID) (*models.Item, error) {
+	var item models.Item
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&item, "id = ? AND is_active = ?", id, false).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
-func (r *itemRepository) Delete(id uuid.UUID) error {
-	return r.db.Model(&models.Item{}).Where("id = ?", id).Update("is_active", false).Error
+// IsReferenced reports whether the item has any recorded history
+func (r *itemRepository) IsReferenced(id uuid.UUID) (bool, error) {
+	return isReferenced(r.db, itemReferences, id)
 }
 
-func (r *itemRepository) GetSummary() (*models.DashboardSummary, error) {
+// Purge permanently removes an item
+func (r *itemRepository) Purge(id uuid.UUID) error {
+	return r.db.Delete(&models.Item{}, "id = ?", id).Error
+}
+
+func (r *itemRepository) GetSummary(scope models.AccessScope) (*models.DashboardSummary, error) {
 	var summary models.DashboardSummary
 
+	items := func() *gorm.DB {
+		return scopeItems(r.db.Model(&models.Item{}), scope)
+	}
+
 	// Total items
-	r.db.Model(&models.Item{}).Where("is_active = ?", true).Count(&summary.TotalItems)
+	items().Where("is_active = ?", true).Count(&summary.TotalItems)
 
 	// Items in warehouse
-	r.db.Model(&models.Item{}).Where("is_active = ? AND current_location = ?", true, models.LocationWarehouse).Count(&summary.ItemsInWarehouse)
+	items().Where("is_active = ? AND current_location = ?", true, models.LocationWarehouse).Count(&summary.ItemsInWarehouse)
 
 	// Items in OPD
-	r.db.Model(&models.Item{}).Where("is_active = ? AND current_location = ?", true, models.LocationOPD).Count(&summary.ItemsInOPD)
+	items().Where("is_active = ? AND current_location = ?", true, models.LocationOPD).Count(&summary.ItemsInOPD)
+
+	// Items in transit
+	items().Where("is_active = ? AND current_location = ?", true, models.LocationInTransit).Count(&summary.ItemsInTransit)
+
+	// Items written off by a disposal
+	items().Where("is_active = ? AND current_location = ?", true, models.LocationDisposed).Count(&summary.ItemsDisposed)
+
+	// Items with an open maintenance ticket
+	scopeMaintenanceTickets(r.db.Model(&models.MaintenanceTicket{}), scope).
+		Where("status IN ?", openMaintenanceStatuses).
+		Count(&summary.ItemsInMaintenance)
 
 	// Total transactions
-	r.db.Model(&models.Transaction{}).Count(&summary.TotalTransactions)
+	scopeTransactions(r.db.Model(&models.Transaction{}), scope).Count(&summary.TotalTransactions)
+
+	// Loans still out, and those past their due date
+	now := time.Now()
+	openLoans := func() *gorm.DB {
+		return scopeTransactions(r.db.Model(&models.Transaction{}), scope).
+			Scopes(loans).Where("loan_returned_at IS NULL")
+	}
+	openLoans().Count(&summary.OpenLoans)
+	openLoans().Where("loan_due_date < ?", now).Count(&summary.OverdueLoans)
+
+	var overdueSummaries []models.OPDSummary
+	openLoans().
+		Select("opds.id as opd_id, opds.parent_id, opds.name as opd_name, COUNT(*) as count").
+		Joins("JOIN opds ON transactions.target_opd_id = opds.id").
+		Where("transactions.loan_due_date < ?", now).
+		Group("opds.id, opds.parent_id, opds.name").
+		Scan(&overdueSummaries)
+	summary.OverdueLoansByOPD = overdueSummaries
 
 	// Items by condition
 	summary.ItemsByCondition = make(map[models.Condition]int64)
-	conditions := []models.Condition{models.ConditionGood, models.ConditionPartial, models.ConditionBroken}
-	for _, condition := range conditions {
+	for _, condition := range models.Conditions {
 		var count int64
-		r.db.Model(&models.Item{}).Where("is_active = ? AND condition = ?", true, condition).Count(&count)
+		items().Where("is_active = ? AND condition = ?", true, condition).Count(&count)
 		summary.ItemsByCondition[condition] = count
 	}
 
 	// Items by category
 	var categorySummaries []models.CategorySummary
-	r.db.Model(&models.Item{}).
+	items().
 		Select("categories.name as category_name, COUNT(*) as count").
 		Joins("JOIN categories ON items.category_id = categories.id").
 		Where("items.is_active = ? AND categories.is_active = ?", true, true).
@@ -156,13 +361,24 @@ func (r *itemRepository) GetSummary() (*models.DashboardSummary, error) {
 
 	// Items by OPD
 	var opdSummaries []models.OPDSummary
-	r.db.Model(&models.Item{}).
-		Select("opds.name as opd_name, COUNT(*) as count").
+	items().
+		Select("opds.id as opd_id, opds.parent_id, opds.name as opd_name, COUNT(*) as count").
 		Joins("JOIN opds ON items.current_opd_id = opds.id").
 		Where("items.is_active = ? AND items.current_location = ? AND opds.is_active = ?", true, models.LocationOPD, true).
-		Group("opds.name").
+		Group("opds.id, opds.parent_id, opds.name").
 		Scan(&opdSummaries)
 	summary.ItemsByOPD = opdSummaries
 
+	// Items by warehouse
+	var warehouseSummaries []models.WarehouseSummary
+	items().
+		Select("warehouses.id as warehouse_id, warehouses.name as warehouse_name, COUNT(*) as count").
+		Joins("JOIN warehouses ON items.current_warehouse_id = warehouses.id").
+		Where("items.is_active = ? AND items.current_location = ?", true, models.LocationWarehouse).
+		Group("warehouses.id, warehouses.name").
+		Order("warehouses.name").
+		Scan(&warehouseSummaries)
+	summary.ItemsByWarehouse = warehouseSummaries
+
 	return &summary, nil
 }
\ No newline at end of file
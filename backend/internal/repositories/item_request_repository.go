@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ItemRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewItemRequestRepository(db *gorm.DB) *ItemRequestRepository {
+	return &ItemRequestRepository{db: db}
+}
+
+func (r *ItemRequestRepository) GetItemRequests(scope models.AccessScope, params *models.ItemRequestSearchParams) ([]models.ItemRequest, int64, error) {
+	var requests []models.ItemRequest
+	var total int64
+
+	query := scopeItemRequests(r.db.Model(&models.ItemRequest{}), scope)
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("opd_id = ?", opdUUID)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("OPD").Preload("Lines").Preload("Lines.Category").
+		Offset(offset).Limit(params.Limit).Order("created_at DESC").
+		Find(&requests).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return requests, total, nil
+}
+
+// CreateItemRequest inserts the request with its lines and first event
+func (r *ItemRequestRepository) CreateItemRequest(request *models.ItemRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *ItemRequestRepository) GetItemRequest(id string) (*models.ItemRequest, error) {
+	var request models.ItemRequest
+	err := r.db.Preload("OPD").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Lines.Category").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		First(&request, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetItemRequestForUpdate locks a request and loads its lines, so that
+// approvals and deliveries of it are serialized
+func (r *ItemRequestRepository) GetItemRequestForUpdate(id string) (*models.ItemRequest, error) {
+	var request models.ItemRequest
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("request_id = ?", request.ID).Order("created_at").Find(&request.Lines).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *ItemRequestRepository) UpdateItemRequest(request *models.ItemRequest) error {
+	return r.db.Omit(clause.Associations).Save(request).Error
+}
+
+func (r *ItemRequestRepository) UpdateLine(line *models.ItemRequestLine) error {
+	return r.db.Omit(clause.Associations).Save(line).Error
+}
+
+func (r *ItemRequestRepository) CreateEvent(event *models.ItemRequestEvent) error {
+	return r.db.Create(event).Error
+}
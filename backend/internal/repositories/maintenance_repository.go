@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MaintenanceRepository struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceRepository(db *gorm.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+var openMaintenanceStatuses = []models.MaintenanceStatus{models.MaintenanceReported, models.MaintenanceInRepair}
+
+func (r *MaintenanceRepository) GetMaintenanceTickets(scope models.AccessScope, params *models.MaintenanceSearchParams) ([]models.MaintenanceTicket, int64, error) {
+	var tickets []models.MaintenanceTicket
+	var total int64
+
+	query := scopeMaintenanceTickets(r.db.Model(&models.MaintenanceTicket{}), scope)
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if params.Open {
+		query = query.Where("status IN ?", openMaintenanceStatuses)
+	}
+
+	if params.ItemID != "" {
+		if itemUUID, err := uuid.Parse(params.ItemID); err == nil {
+			query = query.Where("item_id = ?", itemUUID)
+		}
+	}
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("opd_id = ?", opdUUID)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("Item").Preload("Item.Category").Preload("OPD").
+		Offset(offset).Limit(params.Limit).Order("created_at DESC").
+		Find(&tickets).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tickets, total, nil
+}
+
+func (r *MaintenanceRepository) CreateMaintenanceTicket(ticket *models.MaintenanceTicket) error {
+	return r.db.Omit(clause.Associations).Create(ticket).Error
+}
+
+func (r *MaintenanceRepository) GetMaintenanceTicket(id string) (*models.MaintenanceTicket, error) {
+	var ticket models.MaintenanceTicket
+	err := r.db.Preload("Item").Preload("Item.Category").Preload("Item.CurrentOPD").Preload("OPD").
+		First(&ticket, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// FindOpenTicket returns the open ticket of an item, if any
+func (r *MaintenanceRepository) FindOpenTicket(itemID uuid.UUID) (*models.MaintenanceTicket, error) {
+	var ticket models.MaintenanceTicket
+	err := r.db.Where("item_id = ? AND status IN ?", itemID, openMaintenanceStatuses).First(&ticket).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// FindBlockingItems returns up to limit of itemIDs that have an open
+// ticket, and how many there are in all
+func (r *MaintenanceRepository) FindBlockingItems(itemIDs []uuid.UUID, limit int) ([]models.BlockingItem, int64, error) {
+	if len(itemIDs) == 0 {
+		return nil, 0, nil
+	}
+	open := r.db.Model(&models.MaintenanceTicket{}).Select("item_id").
+		Where("status IN ?", openMaintenanceStatuses)
+	query := r.db.Model(&models.Item{}).Where("id IN ? AND id IN (?)", itemIDs, open)
+	return findBlockingItems(query, limit)
+}
+
+func (r *MaintenanceRepository) UpdateMaintenanceTicket(ticket *models.MaintenanceTicket) error {
+	return r.db.Omit(clause.Associations).Save(ticket).Error
+}
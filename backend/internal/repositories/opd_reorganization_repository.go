@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OPDReorganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOPDReorganizationRepository(db *gorm.DB) *OPDReorganizationRepository {
+	return &OPDReorganizationRepository{db: db}
+}
+
+func (r *OPDReorganizationRepository) GetReorganizations(params *models.ReorganizationSearchParams) ([]models.OPDReorganization, int64, error) {
+	var reorganizations []models.OPDReorganization
+	var total int64
+
+	query := r.db.Model(&models.OPDReorganization{})
+
+	if params.Kind != "" {
+		query = query.Where("kind = ?", params.Kind)
+	}
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("id IN (?)", r.db.Model(&models.OPDLineage{}).Select("reorganization_id").
+				Where("predecessor_id = ? OR successor_id = ?", opdUUID, opdUUID))
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("Lineage").Preload("Lineage.Predecessor").Preload("Lineage.Successor").
+		Offset(offset).Limit(params.Limit).Order("effective_at DESC").Find(&reorganizations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reorganizations, total, nil
+}
+
+// CreateReorganization inserts the reorganization with its lineage
+func (r *OPDReorganizationRepository) CreateReorganization(reorganization *models.OPDReorganization) error {
+	return r.db.Create(reorganization).Error
+}
+
+func (r *OPDReorganizationRepository) GetReorganization(id string) (*models.OPDReorganization, error) {
+	var reorganization models.OPDReorganization
+	err := r.db.Preload("Lineage").Preload("Lineage.Predecessor").Preload("Lineage.Successor").
+		First(&reorganization, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reorganization, nil
+}
+
+// GetLineage lists the links from and to an OPD, oldest first
+func (r *OPDReorganizationRepository) GetLineage(opdID uuid.UUID) ([]models.OPDLineage, error) {
+	var lineage []models.OPDLineage
+	err := r.db.Preload("Reorganization").Preload("Predecessor").Preload("Successor").
+		Joins("JOIN opd_reorganizations ON opd_reorganizations.id = opd_lineages.reorganization_id").
+		Where("opd_lineages.predecessor_id = ? OR opd_lineages.successor_id = ?", opdID, opdID).
+		Order("opd_reorganizations.effective_at").
+		Find(&lineage).Error
+	if err != nil {
+		return nil, err
+	}
+	return lineage, nil
+}
+
+// FindInTransit returns the serial numbers of items on their way into or
+// out of an OPD
+func (r *OPDReorganizationRepository) FindInTransit(opdID uuid.UUID) ([]string, error) {
+	var serialNumbers []string
+	err := r.db.Model(&models.Transaction{}).
+		Joins("JOIN items ON items.id = transactions.item_id").
+		Where("transactions.status = ? AND (transactions.source_opd_id = ? OR transactions.target_opd_id = ?)",
+			models.TransactionInTransit, opdID, opdID).
+		Order("items.serial_number").
+		Pluck("items.serial_number", &serialNumbers).Error
+	return serialNumbers, err
+}
+
+// ReassignUsers moves the accounts of one OPD to another
+func (r *OPDReorganizationRepository) ReassignUsers(fromOPDID, toOPDID uuid.UUID) error {
+	return r.db.Model(&models.User{}).Where("opd_id = ?", fromOPDID).Update("opd_id", toOPDID).Error
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"warehouse-system/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -38,6 +39,97 @@ func (r *OPDRepository) UpdateOPD(id string, opd *models.OPD) error {
 	return r.db.Where("id = ?", id).Updates(opd).Error
 }
 
-func (r *OPDRepository) DeleteOPD(id string) error {
-	return r.db.Model(&models.OPD{}).Where("id = ?", id).Update("is_active", false).Error
+// UpdateDeletion stores the SoftDelete fields of an OPD
+func (r *OPDRepository) UpdateDeletion(opd *models.OPD) error {
+	return r.db.Model(opd).Select(softDeleteColumns).Updates(opd).Error
+}
+
+// GetDeletedOPDs lists OPDs in the trash, most recently deleted first
+func (r *OPDRepository) GetDeletedOPDs() ([]models.OPD, error) {
+	var opds []models.OPD
+	if err := r.db.Where("is_active = ?", false).Order("deleted_at DESC NULLS LAST").Find(&opds).Error; err != nil {
+		return nil, err
+	}
+	return opds, nil
+}
+
+// FindActiveByName returns the active OPD with the given name, if any
+func (r *OPDRepository) FindActiveByName(name string) (*models.OPD, error) {
+	var opd models.OPD
+	if err := r.db.First(&opd, "name = ? AND is_active = ?", name, true).Error; err != nil {
+		return nil, err
+	}
+	return &opd, nil
+}
+
+// CountItems returns how many active items each OPD holds itself
+func (r *OPDRepository) CountItems() (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		OPDID uuid.UUID
+		Count int64
+	}
+	err := r.db.Model(&models.Item{}).
+		Select("current_opd_id AS opd_id, COUNT(*) AS count").
+		Where("is_active = ? AND current_location = ?", true, models.LocationOPD).
+		Group("current_opd_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.OPDID] = row.Count
+	}
+	return counts, nil
+}
+
+// HasActiveChildren reports whether active OPDs sit directly under id
+func (r *OPDRepository) HasActiveChildren(id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.OPD{}).Where("parent_id = ? AND is_active = ?", id, true).Count(&count).Error
+	return count > 0, err
+}
+
+// GetActiveChildren lists the active OPDs directly under id
+func (r *OPDRepository) GetActiveChildren(id uuid.UUID) ([]models.OPD, error) {
+	var opds []models.OPD
+	if err := r.db.Where("parent_id = ? AND is_active = ?", id, true).Order("name").Find(&opds).Error; err != nil {
+		return nil, err
+	}
+	return opds, nil
+}
+
+// MoveSubtree stores the new parent and path of a moved OPD and rewrites
+// the paths of the units under it
+func (r *OPDRepository) MoveSubtree(opd *models.OPD, oldPath string) error {
+	err := r.db.Model(&models.OPD{}).Where("id = ?", opd.ID).
+		Updates(map[string]interface{}{"parent_id": opd.ParentID, "path": opd.Path}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Exec(
+		"UPDATE opds SET path = ? || substr(path, ?) WHERE path LIKE ? AND id <> ?",
+		opd.Path, len(oldPath)+1, oldPath+"%", opd.ID,
+	).Error
+}
+
+// FindBlockingItems returns up to limit active items that the OPD holds or
+// that are in transit to or from it, and how many there are in all
+func (r *OPDRepository) FindBlockingItems(id uuid.UUID, limit int) ([]models.BlockingItem, int64, error) {
+	inTransit := r.db.Model(&models.Transaction{}).Select("item_id").
+		Where("status = ? AND (source_opd_id = ? OR target_opd_id = ?)", models.TransactionInTransit, id, id)
+	query := r.db.Model(&models.Item{}).
+		Where("is_active = ? AND (current_opd_id = ? OR id IN (?))", true, id, inTransit)
+	return findBlockingItems(query, limit)
+}
+
+// IsReferenced reports whether any other record still points at the OPD
+func (r *OPDRepository) IsReferenced(id uuid.UUID) (bool, error) {
+	return isReferenced(r.db, opdReferences, id)
+}
+
+// Purge permanently removes an OPD
+func (r *OPDRepository) Purge(id uuid.UUID) error {
+	return r.db.Delete(&models.OPD{}, "id = ?", id).Error
 }
\ No newline at end of file
@@ -5,17 +5,52 @@ import (
 )
 
 type Repositories struct {
-	Item        ItemRepository
-	Transaction TransactionRepository
-	OPD         OPDRepository
-	Category    CategoryRepository
+	db *gorm.DB
+
+	Item           ItemRepository
+	Transaction    *TransactionRepository
+	OPD            *OPDRepository
+	Category       *CategoryRepository
+	User           *UserRepository
+	Audit          *AuditRepository
+	Handover       *HandoverRepository
+	StockOpname    *StockOpnameRepository
+	Transfer       *TransferBatchRepository
+	ItemRequest    *ItemRequestRepository
+	Maintenance    *MaintenanceRepository
+	Disposal       *DisposalRepository
+	Storage        *StorageLocationRepository
+	Warehouse      *WarehouseRepository
+	Reorganization *OPDReorganizationRepository
+	Custodian      *CustodianRepository
 }
 
 func NewRepositories(db *gorm.DB) *Repositories {
 	return &Repositories{
-		Item:        NewItemRepository(db),
-		Transaction: NewTransactionRepository(db),
-		OPD:         NewOPDRepository(db),
-		Category:    NewCategoryRepository(db),
+		db:             db,
+		Item:           NewItemRepository(db),
+		Transaction:    NewTransactionRepository(db),
+		OPD:            NewOPDRepository(db),
+		Category:       NewCategoryRepository(db),
+		User:           NewUserRepository(db),
+		Audit:          NewAuditRepository(db),
+		Handover:       NewHandoverRepository(db),
+		StockOpname:    NewStockOpnameRepository(db),
+		Transfer:       NewTransferBatchRepository(db),
+		ItemRequest:    NewItemRequestRepository(db),
+		Maintenance:    NewMaintenanceRepository(db),
+		Disposal:       NewDisposalRepository(db),
+		Storage:        NewStorageLocationRepository(db),
+		Warehouse:      NewWarehouseRepository(db),
+		Reorganization: NewOPDReorganizationRepository(db),
+		Custodian:      NewCustodianRepository(db),
 	}
-}
\ No newline at end of file
+}
+
+// WithinTransaction runs fn with repositories bound to a single database
+// transaction. Returning an error from fn rolls every write back.
+func (r *Repositories) WithinTransaction(fn func(tx *Repositories) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepositories(tx))
+	})
+}
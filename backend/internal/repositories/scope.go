@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// scopeItems limits an items query to the OPD of a restricted scope
+func scopeItems(query *gorm.DB, scope models.AccessScope) *gorm.DB {
+	if !scope.Restricted {
+		return query
+	}
+	return query.Where("items.current_location = ? AND items.current_opd_id = ?", models.LocationOPD, scopeOPDID(scope))
+}
+
+// scopeTransactions limits a transactions query to movements into or out of
+// the OPD of a restricted scope
+func scopeTransactions(query *gorm.DB, scope models.AccessScope) *gorm.DB {
+	if !scope.Restricted {
+		return query
+	}
+	opdID := scopeOPDID(scope)
+	return query.Where("(transactions.source_opd_id = ? OR transactions.target_opd_id = ?)", opdID, opdID)
+}
+
+// scopeHandovers limits a handover documents query to documents handing
+// items to or from the OPD of a restricted scope
+func scopeHandovers(query *gorm.DB, scope models.AccessScope) *gorm.DB {
+	if !scope.Restricted {
+		return query
+	}
+	opdID := scopeOPDID(scope)
+	return query.Where("(handover_documents.source_opd_id = ? OR handover_documents.target_opd_id = ?)", opdID, opdID)
+}
+
+// scopeTransferBatches limits a transfer batch query to batches moving
+// items into or out of the OPD of a restricted scope
+func scopeTransferBatches(query *gorm.DB, scope models.AccessScope) *gorm.DB {
+	if !scope.Restricted {
+		return query
+	}
+	opdID := scopeOPDID(scope)
+	return query.Where("(transfer_batches.source_opd_id = ? OR transfer_batches.target_opd_id = ?)", opdID, opdID)
+}
+
+// scopeItemRequests limits an item request query to requests of the OPD of
+// a restricted scope
+func scopeItemRequests(query *gorm.DB, scope models.AccessScope) *gorm.DB {
+	if !scope.Restricted {
+		return query
+	}
+	return query.Where("item_requests.opd_id = ?", scopeOPDID(scope))
+}
+
+// scopeMaintenanceTickets limits a maintenance ticket query to tickets of
+// items reported while held by the OPD of a restricted scope
+func scopeMaintenanceTickets(query *gorm.DB, scope models.AccessScope) *gorm.DB {
+	if !scope.Restricted {
+		return query
+	}
+	return query.Where("maintenance_tickets.opd_id = ?", scopeOPDID(scope))
+}
+
+// scopeStockOpnames limits a stock opname query to counts of the OPD of a
+// restricted scope
+func scopeStockOpnames(query *gorm.DB, scope models.AccessScope) *gorm.DB {
+	if !scope.Restricted {
+		return query
+	}
+	return query.Where("stock_opnames.location = ? AND stock_opnames.opd_id = ?", models.LocationOPD, scopeOPDID(scope))
+}
+
+// scopeCustodians limits a custodian query to employees of the OPD of a
+// restricted scope
+func scopeCustodians(query *gorm.DB, scope models.AccessScope) *gorm.DB {
+	if !scope.Restricted {
+		return query
+	}
+	return query.Where("custodians.opd_id = ?", scopeOPDID(scope))
+}
+
+func scopeOPDID(scope models.AccessScope) uuid.UUID {
+	if scope.OPDID == nil {
+		return uuid.Nil
+	}
+	return *scope.OPDID
+}
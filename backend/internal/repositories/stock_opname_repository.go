@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// opnameEntryBatchSize keeps the snapshot insert under the bind parameter
+// limit of a single statement
+const opnameEntryBatchSize = 500
+
+type StockOpnameRepository struct {
+	db *gorm.DB
+}
+
+func NewStockOpnameRepository(db *gorm.DB) *StockOpnameRepository {
+	return &StockOpnameRepository{db: db}
+}
+
+func (r *StockOpnameRepository) GetStockOpnames(scope models.AccessScope, params *models.StockOpnameSearchParams) ([]models.StockOpname, int64, error) {
+	var opnames []models.StockOpname
+	var total int64
+
+	query := scopeStockOpnames(r.db.Model(&models.StockOpname{}), scope)
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if params.Location != "" {
+		query = query.Where("location = ?", params.Location)
+	}
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("opd_id = ?", opdUUID)
+		}
+	}
+
+	if params.WarehouseID != "" {
+		if warehouseUUID, err := uuid.Parse(params.WarehouseID); err == nil {
+			query = query.Where("warehouse_id = ?", warehouseUUID)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Preload("OPD").Preload("Warehouse").Offset(offset).Limit(params.Limit).Order("created_at DESC").Find(&opnames).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return opnames, total, nil
+}
+
+// CreateStockOpname inserts the session and its snapshot entries
+func (r *StockOpnameRepository) CreateStockOpname(opname *models.StockOpname) error {
+	if err := r.db.Omit(clause.Associations).Create(opname).Error; err != nil {
+		return err
+	}
+	if len(opname.Entries) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(opname.Entries, opnameEntryBatchSize).Error
+}
+
+func (r *StockOpnameRepository) GetStockOpname(id string) (*models.StockOpname, error) {
+	var opname models.StockOpname
+	err := r.db.Preload("OPD").Preload("Warehouse").
+		Preload("Entries", func(db *gorm.DB) *gorm.DB { return db.Order("serial_number") }).
+		First(&opname, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &opname, nil
+}
+
+// GetStockOpnameForUpdate loads a session without entries and locks it, so
+// scans and closing the session are serialized
+func (r *StockOpnameRepository) GetStockOpnameForUpdate(id string) (*models.StockOpname, error) {
+	var opname models.StockOpname
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&opname, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &opname, nil
+}
+
+// LockSite locks the warehouse or OPD row being counted, so that sessions
+// of one site are opened one at a time
+func (r *StockOpnameRepository) LockSite(location models.LocationType, siteID uuid.UUID) error {
+	var site interface{} = &models.OPD{}
+	if location == models.LocationWarehouse {
+		site = &models.Warehouse{}
+	}
+	return r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(site, "id = ?", siteID).Error
+}
+
+// FindOpenStockOpname returns the open session of a warehouse or OPD, if any
+func (r *StockOpnameRepository) FindOpenStockOpname(location models.LocationType, siteID uuid.UUID) (*models.StockOpname, error) {
+	var opname models.StockOpname
+	query := r.db.Where("status = ? AND location = ?", models.StockOpnameOpen, location)
+	if location == models.LocationWarehouse {
+		query = query.Where("warehouse_id = ?", siteID)
+	} else {
+		query = query.Where("opd_id = ?", siteID)
+	}
+	if err := query.First(&opname).Error; err != nil {
+		return nil, err
+	}
+	return &opname, nil
+}
+
+func (r *StockOpnameRepository) UpdateStockOpname(opname *models.StockOpname) error {
+	return r.db.Omit(clause.Associations).Save(opname).Error
+}
+
+func (r *StockOpnameRepository) FindEntry(opnameID uuid.UUID, serialNumber string) (*models.StockOpnameEntry, error) {
+	var entry models.StockOpnameEntry
+	err := r.db.Where("opname_id = ? AND serial_number = ?", opnameID, serialNumber).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *StockOpnameRepository) SaveEntry(entry *models.StockOpnameEntry) error {
+	return r.db.Save(entry).Error
+}
+
+// SetEntryResults stores the result of every entry of a session, grouped
+// by result
+func (r *StockOpnameRepository) SetEntryResults(entries []models.StockOpnameEntry) error {
+	byResult := make(map[models.StockOpnameResult][]uuid.UUID)
+	for _, entry := range entries {
+		byResult[entry.Result] = append(byResult[entry.Result], entry.ID)
+	}
+	const chunkSize = 1000
+	for result, ids := range byResult {
+		for start := 0; start < len(ids); start += chunkSize {
+			end := start + chunkSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			err := r.db.Model(&models.StockOpnameEntry{}).Where("id IN ?", ids[start:end]).Update("result", result).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StorageLocationRepository struct {
+	db *gorm.DB
+}
+
+func NewStorageLocationRepository(db *gorm.DB) *StorageLocationRepository {
+	return &StorageLocationRepository{db: db}
+}
+
+// siteOf limits a storage location query to one site, the warehouse or OPD
+// siteID
+func siteOf(query *gorm.DB, site models.LocationType, siteID uuid.UUID) *gorm.DB {
+	if site == models.LocationWarehouse {
+		return query.Where("storage_locations.warehouse_id = ?", siteID)
+	}
+	return query.Where("storage_locations.opd_id = ?", siteID)
+}
+
+// GetStorageLocations lists every node of a site ordered by name
+func (r *StorageLocationRepository) GetStorageLocations(site models.LocationType, siteID uuid.UUID) ([]models.StorageLocation, error) {
+	var locations []models.StorageLocation
+	if err := siteOf(r.db.Model(&models.StorageLocation{}), site, siteID).Order("name").Find(&locations).Error; err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (r *StorageLocationRepository) GetStorageLocation(id string) (*models.StorageLocation, error) {
+	var location models.StorageLocation
+	if err := r.db.First(&location, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *StorageLocationRepository) CreateStorageLocation(location *models.StorageLocation) error {
+	return r.db.Omit(clause.Associations).Create(location).Error
+}
+
+func (r *StorageLocationRepository) UpdateStorageLocation(location *models.StorageLocation) error {
+	return r.db.Omit(clause.Associations).Save(location).Error
+}
+
+// FindSibling returns the node of the site named name directly under
+// parentID (at the root when nil), if any
+func (r *StorageLocationRepository) FindSibling(site models.LocationType, siteID uuid.UUID, parentID *uuid.UUID, name string) (*models.StorageLocation, error) {
+	query := siteOf(r.db.Model(&models.StorageLocation{}), site, siteID).Where("LOWER(name) = LOWER(?)", name)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	var location models.StorageLocation
+	if err := query.First(&location).Error; err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// RenameSubtree rewrites the full names under a renamed node, whose own row
+// is already saved, and the specific location of the items placed there
+func (r *StorageLocationRepository) RenameSubtree(location *models.StorageLocation, oldFullName string) error {
+	err := r.db.Exec(
+		"UPDATE storage_locations SET full_name = ? || substr(full_name, ?) WHERE path LIKE ? AND id <> ?",
+		location.FullName, len([]rune(oldFullName))+1, location.Path+"%", location.ID,
+	).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Exec(
+		"UPDATE items SET specific_location = storage_locations.full_name FROM storage_locations "+
+			"WHERE items.location_id = storage_locations.id AND storage_locations.path LIKE ?",
+		location.Path+"%",
+	).Error
+}
+
+// CountItems returns how many items are placed at each node of a site.
+// Items on their way elsewhere are not counted.
+func (r *StorageLocationRepository) CountItems(site models.LocationType, siteID uuid.UUID) (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		LocationID uuid.UUID
+		Count      int64
+	}
+	nodes := siteOf(r.db.Model(&models.StorageLocation{}), site, siteID).Select("id")
+	err := r.db.Model(&models.Item{}).
+		Select("location_id, COUNT(*) AS count").
+		Where("is_active = ? AND current_location <> ? AND location_id IN (?)", true, models.LocationInTransit, nodes).
+		Group("location_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.LocationID] = row.Count
+	}
+	return counts, nil
+}
+
+// IsReferenced reports whether sub-locations, items or history still point
+// at the node
+func (r *StorageLocationRepository) IsReferenced(id uuid.UUID) (bool, error) {
+	return isReferenced(r.db, storageLocationReferences, id)
+}
+
+func (r *StorageLocationRepository) DeleteStorageLocation(id uuid.UUID) error {
+	return r.db.Delete(&models.StorageLocation{}, "id = ?", id).Error
+}
+
+func (r *StorageLocationRepository) CreateItemMove(move *models.ItemMove) error {
+	return r.db.Omit(clause.Associations).Create(move).Error
+}
+
+// GetItemMoves lists the moves of an item within its sites, latest first
+func (r *StorageLocationRepository) GetItemMoves(itemID uuid.UUID) ([]models.ItemMove, error) {
+	var moves []models.ItemMove
+	err := r.db.Preload("FromLocation").Preload("ToLocation").
+		Where("item_id = ?", itemID).
+		Order("moved_at DESC").
+		Find(&moves).Error
+	if err != nil {
+		return nil, err
+	}
+	return moves, nil
+}
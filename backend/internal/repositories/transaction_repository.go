@@ -1,8 +1,11 @@
 package repositories
 
 import (
+	"time"
+
 	"warehouse-system/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -14,15 +17,15 @@ func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
-func (r *TransactionRepository) GetTransactions(page, limit int, direction string) ([]models.Transaction, int64, error) {
+// GetTransactions lists transactions, newest first. from and to are
+// inclusive bounds on the transaction date and may be zero.
+func (r *TransactionRepository) GetTransactions(scope models.AccessScope, params *models.TransactionSearchParams, from, to time.Time) ([]models.Transaction, int64, error) {
 	var transactions []models.Transaction
 	var total int64
 
-	query := r.db.Model(&models.Transaction{}).Preload("Item").Preload("SourceOPD").Preload("TargetOPD")
-
-	if direction != "" && direction != "all-directions" {
-		query = query.Where("direction = ?", direction)
-	}
+	query := r.filter(scope, params, from, to).Preload("Item").Preload("SourceOPD").Preload("TargetOPD").
+		Preload("SourceWarehouse").Preload("TargetWarehouse").
+		Preload("SourceCustodian").Preload("TargetCustodian")
 
 	// Count total records
 	if err := query.Count(&total).Error; err != nil {
@@ -30,37 +33,252 @@ func (r *TransactionRepository) GetTransactions(page, limit int, direction strin
 	}
 
 	// Get paginated results
-	offset := (page - 1) * limit
-	if err := query.Offset(offset).Limit(limit).Order("transaction_date DESC").Find(&transactions).Error; err != nil {
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Offset(offset).Limit(params.Limit).Order("transaction_date DESC").Find(&transactions).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return transactions, total, nil
 }
 
+// StreamTransactions passes every transaction matching the filters to fn in
+// batches ordered by transaction date, ignoring pagination
+func (r *TransactionRepository) StreamTransactions(scope models.AccessScope, params *models.TransactionSearchParams, from, to time.Time, fn func(transactions []models.Transaction) error) error {
+	var last *models.Transaction
+	for {
+		var transactions []models.Transaction
+		query := r.filter(scope, params, from, to).
+			Preload("Item").Preload("Item.Category").Preload("SourceOPD").Preload("TargetOPD").
+			Preload("SourceWarehouse").Preload("TargetWarehouse").
+			Preload("SourceCustodian").Preload("TargetCustodian").
+			Order("transaction_date, id").
+			Limit(exportBatchSize)
+		if last != nil {
+			query = query.Where("(transaction_date, id) > (?, ?)", last.TransactionDate, last.ID)
+		}
+		if err := query.Find(&transactions).Error; err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+		if err := fn(transactions); err != nil {
+			return err
+		}
+		if len(transactions) < exportBatchSize {
+			return nil
+		}
+		last = &transactions[len(transactions)-1]
+	}
+}
+
+// filter builds the transactions query shared by listing and export
+func (r *TransactionRepository) filter(scope models.AccessScope, params *models.TransactionSearchParams, from, to time.Time) *gorm.DB {
+	query := scopeTransactions(r.db.Model(&models.Transaction{}), scope)
+
+	if params.Direction != "" && params.Direction != "all-directions" {
+		query = query.Where("direction = ?", params.Direction)
+	}
+
+	if params.ItemID != "" {
+		if itemUUID, err := uuid.Parse(params.ItemID); err == nil {
+			query = query.Where("item_id = ?", itemUUID)
+		}
+	}
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if params.RequestID != "" {
+		if requestUUID, err := uuid.Parse(params.RequestID); err == nil {
+			query = query.Where("request_id = ?", requestUUID)
+		}
+	}
+
+	if params.BatchID != "" {
+		if batchUUID, err := uuid.Parse(params.BatchID); err == nil {
+			query = query.Where("batch_id = ?", batchUUID)
+		}
+	}
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("(source_opd_id = ? OR target_opd_id = ?)", opdUUID, opdUUID)
+		}
+	}
+
+	if params.WarehouseID != "" {
+		if warehouseUUID, err := uuid.Parse(params.WarehouseID); err == nil {
+			query = query.Where("(source_warehouse_id = ? OR target_warehouse_id = ?)", warehouseUUID, warehouseUUID)
+		}
+	}
+
+	if !from.IsZero() {
+		query = query.Where("transaction_date >= ?", from)
+	}
+
+	if !to.IsZero() {
+		query = query.Where("transaction_date <= ?", to)
+	}
+
+	return query
+}
+
+// UpdateReceipt stores the outcome of an in-transit movement. Other fields
+// of a transaction are never updated.
+func (r *TransactionRepository) UpdateReceipt(transaction *models.Transaction) error {
+	return r.db.Model(transaction).
+		Select("status", "receipt_at", "receipt_by", "receipt_by_id", "receipt_notes", "receipt_condition").
+		Updates(transaction).Error
+}
+
+// GetOverdueReceipts returns the IDs of in-transit movements whose receipt
+// deadline has passed
+func (r *TransactionRepository) GetOverdueReceipts(now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.Transaction{}).
+		Where("status = ? AND receipt_due_at < ?", models.TransactionInTransit, now).
+		Order("receipt_due_at").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// loans narrows a transactions query to loans that took effect
+func loans(db *gorm.DB) *gorm.DB {
+	return db.Where("transactions.loan_due_date IS NOT NULL AND transactions.status NOT IN ?",
+		[]models.TransactionStatus{models.TransactionRejected, models.TransactionExpired})
+}
+
+// GetLoans lists loans ordered by due date. Loans still out at now past
+// their due date are overdue.
+func (r *TransactionRepository) GetLoans(scope models.AccessScope, params *models.LoanSearchParams, now time.Time) ([]models.Transaction, int64, error) {
+	var transactions []models.Transaction
+	var total int64
+
+	query := scopeTransactions(r.db.Model(&models.Transaction{}), scope).Scopes(loans)
+
+	switch params.Status {
+	case models.LoanOpen:
+		query = query.Where("loan_returned_at IS NULL")
+	case models.LoanOverdue:
+		query = query.Where("loan_returned_at IS NULL AND loan_due_date < ?", now)
+	case models.LoanReturned:
+		query = query.Where("loan_returned_at IS NOT NULL")
+	}
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("target_opd_id = ?", opdUUID)
+		}
+	}
+
+	if params.ItemID != "" {
+		if itemUUID, err := uuid.Parse(params.ItemID); err == nil {
+			query = query.Where("item_id = ?", itemUUID)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("Item").Preload("Item.Category").Preload("Item.CurrentOPD").Preload("TargetOPD").
+		Offset(offset).Limit(params.Limit).Order("loan_due_date, transaction_date").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+// GetOpenLoan returns the loan of an item that has not been returned yet
+func (r *TransactionRepository) GetOpenLoan(itemID uuid.UUID) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.Scopes(loans).
+		Where("item_id = ? AND loan_returned_at IS NULL", itemID).
+		Order("transaction_date DESC").
+		First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// GetLoanReturnedBy returns the loan that transaction returnID closed
+func (r *TransactionRepository) GetLoanReturnedBy(returnID uuid.UUID) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := r.db.Where("loan_return_id = ?", returnID).First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// UpdateLoanReturn stores when and by which movement a loan came back
+func (r *TransactionRepository) UpdateLoanReturn(transaction *models.Transaction) error {
+	return r.db.Model(transaction).
+		Select("loan_returned_at", "loan_return_id").
+		Updates(transaction).Error
+}
+
 func (r *TransactionRepository) CreateTransaction(transaction *models.Transaction) error {
 	return r.db.Create(transaction).Error
 }
 
 func (r *TransactionRepository) GetTransaction(id string) (*models.Transaction, error) {
 	var transaction models.Transaction
-	if err := r.db.Preload("Item").Preload("SourceOPD").Preload("TargetOPD").First(&transaction, "id = ?", id).Error; err != nil {
+	err := r.db.Preload("Item").Preload("SourceOPD").Preload("TargetOPD").
+		Preload("SourceWarehouse").Preload("TargetWarehouse").
+		Preload("SourceCustodian").Preload("TargetCustodian").
+		Preload("Reversal").
+		Preload("Edits", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		First(&transaction, "id = ?", id).Error
+	if err != nil {
 		return nil, err
 	}
 	return &transaction, nil
 }
 
-func (r *TransactionRepository) UpdateTransaction(id string, transaction *models.Transaction) error {
-	return r.db.Where("id = ?", id).Updates(transaction).Error
+// GetTransactionsByIDs loads transactions with their item and category, in
+// transaction date order
+func (r *TransactionRepository) GetTransactionsByIDs(ids []uuid.UUID) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Preload("Item").Preload("Item.Category").Preload("SourceOPD").Preload("TargetOPD").
+		Preload("SourceWarehouse").Preload("TargetWarehouse").
+		Preload("Reversal").
+		Where("id IN ?", ids).
+		Order("transaction_date, id").
+		Find(&transactions).Error
+	return transactions, err
 }
 
-func (r *TransactionRepository) DeleteTransaction(id string) error {
-	return r.db.Delete(&models.Transaction{}, "id = ?", id).Error
+// GetLatestForItem returns the most recent movement of an item, skipping
+// movements that were rejected or expired in transit
+func (r *TransactionRepository) GetLatestForItem(itemID uuid.UUID) (*models.Transaction, error) {
+	var transaction models.Transaction
+	query := r.db.Where("item_id = ? AND status NOT IN ?", itemID, []models.TransactionStatus{models.TransactionRejected, models.TransactionExpired})
+	if err := query.Order("transaction_date DESC, created_at DESC").First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// UpdateNotes changes the notes of a transaction and records the edit in the
+// same call. Location fields are never updated in place.
+func (r *TransactionRepository) UpdateNotes(transaction *models.Transaction, edit *models.TransactionEdit) error {
+	if err := r.db.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Update("notes", edit.NewValue).Error; err != nil {
+		return err
+	}
+	return r.db.Create(edit).Error
 }
 
-func (r *TransactionRepository) GetRecentTransactions() ([]models.Transaction, error) {
+func (r *TransactionRepository) GetRecentTransactions(scope models.AccessScope) ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	if err := r.db.Preload("Item").Preload("SourceOPD").Preload("TargetOPD").
+	if err := scopeTransactions(r.db.Model(&models.Transaction{}), scope).
+		Preload("Item").Preload("SourceOPD").Preload("TargetOPD").
+		Preload("SourceWarehouse").Preload("TargetWarehouse").
 		Order("transaction_date DESC").Limit(10).Find(&transactions).Error; err != nil {
 		return nil, err
 	}
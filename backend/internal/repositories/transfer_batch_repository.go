@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"time"
+
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferBatchRepository(db *gorm.DB) *TransferBatchRepository {
+	return &TransferBatchRepository{db: db}
+}
+
+// GetTransferBatches lists batches without their transactions, newest
+// first. from and to are inclusive bounds on the batch date and may be zero.
+func (r *TransferBatchRepository) GetTransferBatches(scope models.AccessScope, params *models.TransferBatchSearchParams, from, to time.Time) ([]models.TransferBatch, int64, error) {
+	var batches []models.TransferBatch
+	var total int64
+
+	query := scopeTransferBatches(r.db.Model(&models.TransferBatch{}), scope)
+
+	if params.Direction != "" {
+		query = query.Where("direction = ?", params.Direction)
+	}
+
+	if params.OPDID != "" {
+		if opdUUID, err := uuid.Parse(params.OPDID); err == nil {
+			query = query.Where("(source_opd_id = ? OR target_opd_id = ?)", opdUUID, opdUUID)
+		}
+	}
+
+	if !from.IsZero() {
+		query = query.Where("batch_date >= ?", from)
+	}
+
+	if !to.IsZero() {
+		query = query.Where("batch_date <= ?", to)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	err := query.Preload("SourceOPD").Preload("TargetOPD").Preload("SourceWarehouse").Preload("TargetWarehouse").
+		Offset(offset).Limit(params.Limit).Order("batch_date DESC").
+		Find(&batches).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return batches, total, nil
+}
+
+// CreateTransferBatch inserts the batch header only, its transactions are
+// recorded one by one as the items move
+func (r *TransferBatchRepository) CreateTransferBatch(batch *models.TransferBatch) error {
+	return r.db.Omit(clause.Associations).Create(batch).Error
+}
+
+func (r *TransferBatchRepository) GetTransferBatch(id string) (*models.TransferBatch, error) {
+	var batch models.TransferBatch
+	err := r.db.Preload("SourceOPD").Preload("TargetOPD").Preload("SourceWarehouse").Preload("TargetWarehouse").
+		Preload("TargetCustodian").
+		Preload("Transactions", func(db *gorm.DB) *gorm.DB { return db.Order("transaction_date, id") }).
+		Preload("Transactions.Item").Preload("Transactions.Item.Category").
+		First(&batch, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
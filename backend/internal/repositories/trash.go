@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// softDeleteColumns are the columns of models.SoftDelete, written together
+// when a row is deleted or restored
+var softDeleteColumns = []string{"is_active", "deleted_at", "deleted_by", "deleted_by_id"}
+
+// reference is a column pointing at the primary key of another table
+type reference struct {
+	table  string
+	column string
+}
+
+var itemReferences = []reference{
+	{"transactions", "item_id"},
+	{"maintenance_tickets", "item_id"},
+	{"disposal_items", "item_id"},
+	{"item_moves", "item_id"},
+}
+
+var opdReferences = []reference{
+	{"opds", "parent_id"},
+	{"items", "current_opd_id"},
+	{"users", "opd_id"},
+	{"custodians", "opd_id"},
+	{"transactions", "source_opd_id"},
+	{"transactions", "target_opd_id"},
+	{"transfer_batches", "source_opd_id"},
+	{"transfer_batches", "target_opd_id"},
+	{"handover_documents", "source_opd_id"},
+	{"handover_documents", "target_opd_id"},
+	{"item_requests", "opd_id"},
+	{"stock_opnames", "opd_id"},
+	{"maintenance_tickets", "opd_id"},
+	{"disposal_items", "opd_id"},
+	{"storage_locations", "opd_id"},
+	{"item_moves", "opd_id"},
+	{"opd_lineages", "predecessor_id"},
+	{"opd_lineages", "successor_id"},
+}
+
+var categoryReferences = []reference{
+	{"items", "category_id"},
+	{"item_request_lines", "category_id"},
+}
+
+var storageLocationReferences = []reference{
+	{"storage_locations", "parent_id"},
+	{"items", "location_id"},
+	{"transactions", "location_id"},
+	{"transactions", "source_location_id"},
+	{"transfer_batches", "location_id"},
+	{"item_moves", "from_location_id"},
+	{"item_moves", "to_location_id"},
+}
+
+// isReferenced reports whether any of refs still points at id. Purging a
+// row that is referenced would break the history that refers to it.
+func isReferenced(db *gorm.DB, refs []reference, id uuid.UUID) (bool, error) {
+	for _, ref := range refs {
+		var exists bool
+		err := db.Raw("SELECT EXISTS (SELECT 1 FROM "+ref.table+" WHERE "+ref.column+" = ?)", id).
+			Scan(&exists).Error
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findBlockingItems counts the items of query and lists the first limit of
+// them by serial number
+func findBlockingItems(query *gorm.DB, limit int) ([]models.BlockingItem, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil || total == 0 {
+		return nil, total, err
+	}
+
+	var items []models.BlockingItem
+	err := query.Select("id, serial_number, brand, model, current_location").
+		Order("serial_number").Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
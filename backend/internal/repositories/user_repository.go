@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"time"
+
+	"warehouse-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) CountUsers() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+func (r *UserRepository) GetUsers() ([]models.User, error) {
+	var users []models.User
+	if err := r.db.Preload("OPD").Order("username").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepository) CreateUser(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *UserRepository) GetUser(id string) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetUserByUsername(username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, "username = ?", username).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) CreateSession(session *models.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *UserRepository) GetSession(id string) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *UserRepository) GetSessionByTokenHash(hash string) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.Preload("User").First(&session, "refresh_token_hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateSession swaps the refresh token of a live session, so a stolen
+// token stops working as soon as the legitimate client refreshes
+func (r *UserRepository) RotateSession(id string, oldHash, newHash string, expiresAt time.Time) error {
+	result := r.db.Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]interface{}{"refresh_token_hash": newHash, "expires_at": expiresAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) RevokeSession(id string) error {
+	return r.db.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WarehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepository(db *gorm.DB) *WarehouseRepository {
+	return &WarehouseRepository{db: db}
+}
+
+// GetWarehouses lists active warehouses, the default one first
+func (r *WarehouseRepository) GetWarehouses() ([]models.Warehouse, error) {
+	var warehouses []models.Warehouse
+	if err := r.db.Where("is_active = ?", true).Order("is_default DESC, name").Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+func (r *WarehouseRepository) CreateWarehouse(warehouse *models.Warehouse) error {
+	return r.db.Create(warehouse).Error
+}
+
+func (r *WarehouseRepository) GetWarehouse(id string) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	if err := r.db.First(&warehouse, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// GetDefault returns the default warehouse
+func (r *WarehouseRepository) GetDefault() (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	if err := r.db.First(&warehouse, "is_default = ? AND is_active = ?", true, true).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (r *WarehouseRepository) UpdateWarehouse(warehouse *models.Warehouse) error {
+	return r.db.Save(warehouse).Error
+}
+
+// ClearDefault unsets the default flag on every warehouse except id
+func (r *WarehouseRepository) ClearDefault(id uuid.UUID) error {
+	return r.db.Model(&models.Warehouse{}).Where("is_default = ? AND id <> ?", true, id).Update("is_default", false).Error
+}
+
+// FindActiveByName returns the active warehouse with the given name, if any
+func (r *WarehouseRepository) FindActiveByName(name string) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	if err := r.db.First(&warehouse, "name = ? AND is_active = ?", name, true).Error; err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// IsHoldingItems reports whether items are in the warehouse or on their way
+// into it
+func (r *WarehouseRepository) IsHoldingItems(id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Item{}).
+		Where("is_active = ? AND current_warehouse_id = ?", true, id).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	err = r.db.Model(&models.Transaction{}).
+		Where("status = ? AND target_warehouse_id = ?", models.TransactionInTransit, id).
+		Count(&count).Error
+	return count > 0, err
+}
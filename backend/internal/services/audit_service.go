@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+type AuditService struct {
+	auditRepo *repositories.AuditRepository
+}
+
+func NewAuditService(auditRepo *repositories.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+func (s *AuditService) GetAuditLogs(params *models.AuditSearchParams) ([]models.AuditLog, int64, error) {
+	from, to, err := parseDateRange(params.From, params.To)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.auditRepo.GetAuditLogs(params, from, to)
+}
+
+// parseDateRange parses the inclusive from/to filters used by list endpoints
+func parseDateRange(fromValue, toValue string) (time.Time, time.Time, error) {
+	from, err := parseDateBound(fromValue, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseDateBound(toValue, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+// parseDateBound accepts RFC 3339 timestamps or plain dates. A plain date
+// used as an upper bound covers the whole day.
+func parseDateBound(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// auditFieldsIgnored are bookkeeping columns that change on every write
+var auditFieldsIgnored = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+}
+
+// recordAudit stores the field-level difference between two snapshots of an
+// entity. before is nil for creations. Pass repositories bound to the same
+// database transaction as the change so both commit together.
+func recordAudit(tx *repositories.Repositories, actor models.Actor, entityType models.AuditEntityType, entityID uuid.UUID, action models.AuditAction, before, after interface{}) error {
+	changes, err := diffFields(before, after)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 && action == models.AuditActionUpdate {
+		return nil
+	}
+
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Audit.CreateAuditLog(&models.AuditLog{
+		ID:         uuid.New(),
+		ActorID:    actor.ID,
+		ActorName:  actor.Name,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    raw,
+	})
+}
+
+// diffFields compares the JSON form of two snapshots. Nested objects such as
+// preloaded associations are skipped; their foreign keys are compared instead.
+func diffFields(before, after interface{}) (map[string]models.FieldChange, error) {
+	old, err := scalarFields(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := scalarFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]models.FieldChange)
+	for key, value := range current {
+		if previous, ok := old[key]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[key] = models.FieldChange{Old: old[key], New: value}
+		}
+	}
+	for key, previous := range old {
+		if _, ok := current[key]; !ok {
+			changes[key] = models.FieldChange{Old: previous, New: nil}
+		}
+	}
+	return changes, nil
+}
+
+func scalarFields(entity interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if entity == nil {
+		return fields, nil
+	}
+	if v := reflect.ValueOf(entity); v.Kind() == reflect.Ptr && v.IsNil() {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+
+	for key, value := range all {
+		if auditFieldsIgnored[key] {
+			continue
+		}
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		}
+		fields[key] = value
+	}
+	return fields, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-system/internal/config"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type AuthService struct {
+	userRepo        *repositories.UserRepository
+	jwtSecret       []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+// accessClaims are the claims carried by an access token. The session ID
+// ties the token to a revocable UserSession.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid"`
+	Username  string `json:"username"`
+}
+
+func NewAuthService(userRepo *repositories.UserRepository, cfg *config.Config) *AuthService {
+	return &AuthService{
+		userRepo:        userRepo,
+		jwtSecret:       []byte(cfg.JWTSecret),
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		refreshTokenTTL: cfg.RefreshTokenTTL,
+	}
+}
+
+func (s *AuthService) Login(req *models.LoginRequest) (*models.TokenResponse, error) {
+	user, err := s.userRepo.GetUserByUsername(req.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if !user.IsActive || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	refreshToken, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.UserSession{
+		BaseModel:        models.BaseModel{ID: uuid.New()},
+		UserID:           user.ID,
+		RefreshTokenHash: hash,
+		ExpiresAt:        time.Now().Add(s.refreshTokenTTL),
+	}
+	if err := s.userRepo.CreateSession(session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session, refreshToken)
+}
+
+func (s *AuthService) Refresh(req *models.RefreshTokenRequest) (*models.TokenResponse, error) {
+	oldHash := hashToken(req.RefreshToken)
+	session, err := s.userRepo.GetSessionByTokenHash(oldHash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) || !session.User.IsActive {
+		return nil, ErrInvalidToken
+	}
+
+	refreshToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session.ExpiresAt = time.Now().Add(s.refreshTokenTTL)
+	if err := s.userRepo.RotateSession(session.ID.String(), oldHash, newHash, session.ExpiresAt); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return s.issueTokens(&session.User, session, refreshToken)
+}
+
+func (s *AuthService) Logout(sessionID string) error {
+	return s.userRepo.RevokeSession(sessionID)
+}
+
+// Authenticate validates an access token and returns the user and session
+// it was issued for
+func (s *AuthService) Authenticate(tokenString string) (*models.User, string, error) {
+	claims := &accessClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, "", ErrInvalidToken
+	}
+
+	session, err := s.userRepo.GetSession(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrInvalidToken
+		}
+		return nil, "", err
+	}
+	if session.RevokedAt != nil || session.UserID.String() != claims.Subject {
+		return nil, "", ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetUser(claims.Subject)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrInvalidToken
+		}
+		return nil, "", err
+	}
+	if !user.IsActive {
+		return nil, "", ErrInvalidToken
+	}
+
+	return user, session.ID.String(), nil
+}
+
+func (s *AuthService) issueTokens(user *models.User, session *models.UserSession, refreshToken string) (*models.TokenResponse, error) {
+	now := time.Now()
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
+		},
+		SessionID: session.ID.String(),
+		Username:  user.Username,
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	if err != nil {
+		return nil, fmt.Errorf("sign access token: %w", err)
+	}
+
+	return &models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+		User:         *user,
+	}, nil
+}
+
+// newRefreshToken returns an opaque random token and the hash stored for it
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
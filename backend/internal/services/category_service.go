@@ -1,36 +1,50 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"time"
 	"warehouse-system/internal/models"
 	"warehouse-system/internal/repositories"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CategoryService struct {
+	repos        *repositories.Repositories
 	categoryRepo *repositories.CategoryRepository
 }
 
-func NewCategoryService(categoryRepo *repositories.CategoryRepository) *CategoryService {
-	return &CategoryService{categoryRepo: categoryRepo}
+func NewCategoryService(repos *repositories.Repositories) *CategoryService {
+	return &CategoryService{
+		repos:        repos,
+		categoryRepo: repos.Category,
+	}
 }
 
 func (s *CategoryService) GetCategories() ([]models.Category, error) {
 	return s.categoryRepo.GetCategories()
 }
 
-func (s *CategoryService) CreateCategory(req *models.CreateCategoryRequest) (*models.Category, error) {
+func (s *CategoryService) CreateCategory(actor models.Actor, req *models.CreateCategoryRequest) (*models.Category, error) {
 	category := &models.Category{
-		ID:          uuid.New().String(),
+		BaseModel:   models.BaseModel{ID: uuid.New()},
 		Name:        req.Name,
 		Description: req.Description,
-		IsActive:    true,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		SoftDelete:  models.Active(),
 	}
 
-	if err := s.categoryRepo.CreateCategory(category); err != nil {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		if err := requireUniqueCategoryName(tx, req.Name, uuid.Nil); err != nil {
+			return err
+		}
+		if err := tx.Category.CreateCategory(category); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityCategory, category.ID, models.AuditActionCreate, nil, category)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -41,20 +55,190 @@ func (s *CategoryService) GetCategory(id string) (*models.Category, error) {
 	return s.categoryRepo.GetCategory(id)
 }
 
-func (s *CategoryService) UpdateCategory(id string, req *models.CreateCategoryRequest) (*models.Category, error) {
-	category := &models.Category{
-		Name:        req.Name,
-		Description: req.Description,
-		UpdatedAt:   time.Now(),
+func (s *CategoryService) UpdateCategory(actor models.Actor, id string, req *models.CreateCategoryRequest) (*models.Category, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.Category.GetCategory(id)
+		if err != nil {
+			return err
+		}
+		if before.IsActive {
+			if err := requireUniqueCategoryName(tx, req.Name, before.ID); err != nil {
+				return err
+			}
+		}
+
+		category := &models.Category{
+			Name:        req.Name,
+			Description: req.Description,
+		}
+		if err := tx.Category.UpdateCategory(id, category); err != nil {
+			return err
+		}
+
+		after, err := tx.Category.GetCategory(id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityCategory, after.ID, models.AuditActionUpdate, before, after)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.categoryRepo.UpdateCategory(id, category); err != nil {
+	return s.categoryRepo.GetCategory(id)
+}
+
+// DeleteCategory moves a category to the trash once no active item belongs
+// to it. With reassignTo its items are moved to that category first, in
+// the same database transaction.
+func (s *CategoryService) DeleteCategory(actor models.Actor, id string, reassignTo *uuid.UUID) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.Category.GetCategory(id)
+		if err != nil {
+			return err
+		}
+		if !before.IsActive {
+			return gorm.ErrRecordNotFound
+		}
+
+		if reassignTo != nil {
+			if err := reassignCategory(tx, actor, before, *reassignTo); err != nil {
+				return err
+			}
+		}
+		items, total, err := tx.Category.FindBlockingItems(before.ID, blockingItemsLimit)
+		if err != nil {
+			return err
+		}
+		if total > 0 {
+			return &BlockedError{Name: before.Name, Items: items, Total: total}
+		}
+
+		after := *before
+		after.MarkDeleted(actor, time.Now())
+		if err := tx.Category.UpdateDeletion(&after); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityCategory, before.ID, models.AuditActionDelete, before, &after)
+	})
+}
+
+func (s *CategoryService) GetDeletedCategories() ([]models.Category, error) {
+	return s.categoryRepo.GetDeletedCategories()
+}
+
+// RestoreCategory takes a category out of the trash, unless an active category has
+// taken its name in the meantime
+func (s *CategoryService) RestoreCategory(actor models.Actor, id string) (*models.Category, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.Category.GetCategory(id)
+		if err != nil {
+			return err
+		}
+		if before.IsActive {
+			return ErrNotInTrash
+		}
+		if err := requireUniqueCategoryName(tx, before.Name, before.ID); err != nil {
+			return err
+		}
+
+		after := *before
+		after.Restore()
+		if err := tx.Category.UpdateDeletion(&after); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityCategory, before.ID, models.AuditActionRestore, before, &after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return s.categoryRepo.GetCategory(id)
 }
 
-func (s *CategoryService) DeleteCategory(id string) error {
-	return s.categoryRepo.DeleteCategory(id)
-}
\ No newline at end of file
+// PurgeCategory permanently removes a category from the trash. A category that is
+// still referenced by any record cannot be purged.
+func (s *CategoryService) PurgeCategory(actor models.Actor, id string) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.Category.GetCategory(id)
+		if err != nil {
+			return err
+		}
+		if before.IsActive {
+			return ErrNotInTrash
+		}
+		referenced, err := tx.Category.IsReferenced(before.ID)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return ErrStillReferenced
+		}
+
+		if err := tx.Category.Purge(before.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityCategory, before.ID, models.AuditActionPurge, before, nil)
+	})
+}
+
+// requireUniqueCategoryName refuses a name already used by another active category
+func requireUniqueCategoryName(tx *repositories.Repositories, name string, excludeID uuid.UUID) error {
+	existing, err := tx.Category.FindActiveByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID == excludeID {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDuplicateName, name)
+}
+
+// reassignCategory moves every active item of category to the category
+// targetID and audits each change
+func reassignCategory(tx *repositories.Repositories, actor models.Actor, category *models.Category, targetID uuid.UUID) error {
+	if targetID == category.ID {
+		return fmt.Errorf("%w: reassign_to is the category being deleted", ErrInvalidReassignment)
+	}
+	target, err := tx.Category.GetCategory(targetID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrInvalidCategory, targetID)
+		}
+		return err
+	}
+	if !target.IsActive {
+		return fmt.Errorf("%w: %s", ErrInvalidCategory, target.Name)
+	}
+
+	var itemIDs []uuid.UUID
+	params := &models.ItemSearchParams{CategoryID: category.ID.String()}
+	err = tx.Item.StreamAll(models.AccessScope{}, params, func(items []models.Item) error {
+		for i := range items {
+			itemIDs = append(itemIDs, items[i].ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, itemID := range sortedIDs(itemIDs) {
+		item, err := tx.Item.GetForUpdate(itemID)
+		if err != nil {
+			return err
+		}
+		before := *item
+		item.CategoryID = target.ID
+		if err := tx.Item.Update(item); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &before, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
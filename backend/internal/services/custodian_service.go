@@ -0,0 +1,343 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+	"warehouse-system/pkg/handover"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CustodianService struct {
+	repos         *repositories.Repositories
+	custodianRepo *repositories.CustodianRepository
+	templatePath  string
+}
+
+func NewCustodianService(repos *repositories.Repositories, templatePath string) *CustodianService {
+	return &CustodianService{
+		repos:         repos,
+		custodianRepo: repos.Custodian,
+		templatePath:  templatePath,
+	}
+}
+
+func (s *CustodianService) GetCustodians(scope models.AccessScope, params *models.CustodianSearchParams) ([]models.Custodian, int64, error) {
+	return s.custodianRepo.GetCustodians(scope, params)
+}
+
+func (s *CustodianService) GetCustodian(scope models.AccessScope, id string) (*models.Custodian, error) {
+	custodian, err := s.custodianRepo.GetCustodian(id)
+	if err != nil {
+		return nil, err
+	}
+	if !custodian.IsActive || !scope.AllowsCustodian(custodian) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return custodian, nil
+}
+
+func (s *CustodianService) CreateCustodian(actor models.Actor, req *models.CreateCustodianRequest) (*models.Custodian, error) {
+	custodian := &models.Custodian{
+		BaseModel:  models.BaseModel{ID: uuid.New()},
+		Name:       req.Name,
+		NIP:        strings.TrimSpace(req.NIP),
+		Position:   req.Position,
+		OPDID:      req.OPDID,
+		SoftDelete: models.Active(),
+	}
+
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		if _, err := requireActiveOPD(tx, req.OPDID); err != nil {
+			return err
+		}
+		if err := requireUniqueNIP(tx, custodian.NIP, uuid.Nil); err != nil {
+			return err
+		}
+		if err := tx.Custodian.CreateCustodian(custodian); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityCustodian, custodian.ID, models.AuditActionCreate, nil, custodian)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.custodianRepo.GetCustodian(custodian.ID.String())
+}
+
+// UpdateCustodian changes a custodian's details. A custodian moves to
+// another OPD only once nobody's items are left with them.
+func (s *CustodianService) UpdateCustodian(actor models.Actor, id string, req *models.CreateCustodianRequest) (*models.Custodian, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.Custodian.GetCustodian(id)
+		if err != nil {
+			return err
+		}
+		if !before.IsActive {
+			return gorm.ErrRecordNotFound
+		}
+
+		after := *before
+		after.OPD = nil
+		after.Name = req.Name
+		after.NIP = strings.TrimSpace(req.NIP)
+		after.Position = req.Position
+		after.OPDID = req.OPDID
+		if err := requireUniqueNIP(tx, after.NIP, before.ID); err != nil {
+			return err
+		}
+		if after.OPDID != before.OPDID {
+			if _, err := requireActiveOPD(tx, after.OPDID); err != nil {
+				return err
+			}
+			if err := requireNoCustodianItems(tx, before); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Custodian.UpdateCustodian(&after); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityCustodian, after.ID, models.AuditActionUpdate, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.custodianRepo.GetCustodian(id)
+}
+
+// DeleteCustodian deletes a custodian once no item is left with them. With
+// reassignTo their items are handed to that custodian first, in the same
+// database transaction.
+func (s *CustodianService) DeleteCustodian(actor models.Actor, id string, reassignTo *uuid.UUID) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.Custodian.GetCustodian(id)
+		if err != nil {
+			return err
+		}
+		if !before.IsActive {
+			return gorm.ErrRecordNotFound
+		}
+
+		if reassignTo != nil {
+			if *reassignTo == before.ID {
+				return fmt.Errorf("%w: reassign_to is the custodian being deleted", ErrInvalidReassignment)
+			}
+			if err := reassignCustodian(tx, actor, before, *reassignTo); err != nil {
+				return err
+			}
+		}
+		if err := requireNoCustodianItems(tx, before); err != nil {
+			return err
+		}
+
+		after := *before
+		after.OPD = nil
+		after.MarkDeleted(actor, time.Now())
+		if err := tx.Custodian.UpdateCustodian(&after); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityCustodian, before.ID, models.AuditActionDelete, before, &after)
+	})
+}
+
+// GetCustodianItems lists the items a custodian is responsible for
+func (s *CustodianService) GetCustodianItems(scope models.AccessScope, id string, params *models.ItemSearchParams) ([]models.Item, int64, error) {
+	custodian, err := s.GetCustodian(scope, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	params.CustodianID = custodian.ID.String()
+	return s.repos.Item.GetAll(scope, params)
+}
+
+// AssignCustodian makes a custodian of the OPD holding an item responsible
+// for it, or clears the custodian. The item does not move, so no
+// transaction is recorded; the audit log keeps the change.
+func (s *CustodianService) AssignCustodian(scope models.AccessScope, actor models.Actor, itemID uuid.UUID, req *models.AssignCustodianRequest) (*models.Item, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		item, err := tx.Item.GetForUpdate(itemID)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsItem(item) {
+			return ErrForbidden
+		}
+		if item.CurrentLocation != models.LocationOPD || item.CurrentOPDID == nil {
+			return fmt.Errorf("%w: only items held by an OPD have a custodian", ErrInvalidItemLocation)
+		}
+		if req.CustodianID != nil {
+			if _, err := requireCustodianOf(tx, *req.CustodianID, *item.CurrentOPDID); err != nil {
+				return err
+			}
+		}
+		if sameID(item.CurrentCustodianID, req.CustodianID) {
+			return nil
+		}
+
+		before := *item
+		item.CurrentCustodianID = req.CustodianID
+		if err := tx.Item.Update(item); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &before, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repos.Item.GetByID(itemID)
+}
+
+// WriteStatementPDF renders the asset statement of a custodian: every item
+// they are responsible for today
+func (s *CustodianService) WriteStatementPDF(scope models.AccessScope, id string, w io.Writer) error {
+	custodian, err := s.GetCustodian(scope, id)
+	if err != nil {
+		return err
+	}
+	tmpl, err := handover.LoadTemplate(s.templatePath)
+	if err != nil {
+		return err
+	}
+
+	statement := handover.Statement{
+		Date:     time.Now(),
+		Name:     custodian.Name,
+		NIP:      custodian.NIP,
+		Position: custodian.Position,
+	}
+	if custodian.OPD != nil {
+		statement.OPD = custodian.OPD.Name
+	}
+	params := &models.ItemSearchParams{CustodianID: custodian.ID.String()}
+	err = s.repos.Item.StreamAll(scope, params, func(items []models.Item) error {
+		for i := range items {
+			statement.Lines = append(statement.Lines, handover.Line{
+				SerialNumber: items[i].SerialNumber,
+				Name:         strings.TrimSpace(items[i].Brand + " " + items[i].Model),
+				Category:     items[i].Category.Name,
+				Condition:    string(items[i].Condition),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return handover.WriteStatement(w, tmpl, statement)
+}
+
+// reassignCustodian hands every item custodian is responsible for to the
+// custodian targetID, who has to work in the same OPD
+func reassignCustodian(tx *repositories.Repositories, actor models.Actor, custodian *models.Custodian, targetID uuid.UUID) error {
+	target, err := requireCustodianOf(tx, targetID, custodian.OPDID)
+	if err != nil {
+		return err
+	}
+
+	var itemIDs []uuid.UUID
+	params := &models.ItemSearchParams{CustodianID: custodian.ID.String()}
+	err = tx.Item.StreamAll(models.AccessScope{}, params, func(items []models.Item) error {
+		for i := range items {
+			itemIDs = append(itemIDs, items[i].ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, itemID := range sortedIDs(itemIDs) {
+		item, err := tx.Item.GetForUpdate(itemID)
+		if err != nil {
+			return err
+		}
+		if !sameID(item.CurrentOPDID, &target.OPDID) {
+			return fmt.Errorf("%w: item %s is not held by the OPD of %s", ErrInvalidCustodian, item.SerialNumber, target.Name)
+		}
+		before := *item
+		item.CurrentCustodianID = &target.ID
+		if err := tx.Item.Update(item); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &before, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireNoCustodianItems refuses while items are with the custodian or on
+// their way to them
+func requireNoCustodianItems(tx *repositories.Repositories, custodian *models.Custodian) error {
+	items, total, err := tx.Custodian.FindBlockingItems(custodian.ID, blockingItemsLimit)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return &BlockedError{Name: custodian.Name, Items: items, Total: total}
+	}
+	return nil
+}
+
+// requireUniqueNIP refuses a NIP already used by another active custodian
+func requireUniqueNIP(tx *repositories.Repositories, nip string, excludeID uuid.UUID) error {
+	existing, err := tx.Custodian.FindActiveByNIP(nip)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID == excludeID {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDuplicateNIP, nip)
+}
+
+// requireCustodianOf loads the custodian id and makes sure they are active
+// and work in the OPD opdID
+func requireCustodianOf(tx *repositories.Repositories, id, opdID uuid.UUID) (*models.Custodian, error) {
+	custodian, err := tx.Custodian.GetCustodian(id.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCustodian, id)
+		}
+		return nil, err
+	}
+	if !custodian.IsActive {
+		return nil, fmt.Errorf("%w: %s is deleted", ErrInvalidCustodian, custodian.Name)
+	}
+	if custodian.OPDID != opdID {
+		return nil, fmt.Errorf("%w: %s works in another OPD", ErrInvalidCustodian, custodian.Name)
+	}
+	return custodian, nil
+}
+
+// custodianIn returns id when it is an active custodian working in the OPD
+// opdID, and nil otherwise
+func custodianIn(tx *repositories.Repositories, id, opdID *uuid.UUID) (*uuid.UUID, error) {
+	if id == nil || opdID == nil {
+		return nil, nil
+	}
+	custodian, err := tx.Custodian.GetCustodian(id.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !custodian.IsActive || custodian.OPDID != *opdID {
+		return nil, nil
+	}
+	return &custodian.ID, nil
+}
@@ -1,18 +1,21 @@
 package services
 
 import (
+	"sort"
 	"warehouse-system/internal/models"
 	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
 )
 
 type DashboardService struct {
-	itemRepo        *repositories.ItemRepository
+	itemRepo        repositories.ItemRepository
 	transactionRepo *repositories.TransactionRepository
 	opdRepo         *repositories.OPDRepository
 	categoryRepo    *repositories.CategoryRepository
 }
 
-func NewDashboardService(itemRepo *repositories.ItemRepository, transactionRepo *repositories.TransactionRepository, opdRepo *repositories.OPDRepository, categoryRepo *repositories.CategoryRepository) *DashboardService {
+func NewDashboardService(itemRepo repositories.ItemRepository, transactionRepo *repositories.TransactionRepository, opdRepo *repositories.OPDRepository, categoryRepo *repositories.CategoryRepository) *DashboardService {
 	return &DashboardService{
 		itemRepo:        itemRepo,
 		transactionRepo: transactionRepo,
@@ -21,76 +24,66 @@ func NewDashboardService(itemRepo *repositories.ItemRepository, transactionRepo
 	}
 }
 
-func (s *DashboardService) GetSummary() (*models.DashboardSummary, error) {
-	// Get all items for statistics
-	items, _, err := s.itemRepo.GetItems(1, 1000, "", "", "")
+// GetSummary returns the dashboard counts. Counts per OPD are rolled up,
+// so a dinas also totals the items of its bidang and seksi.
+func (s *DashboardService) GetSummary(scope models.AccessScope) (*models.DashboardSummary, error) {
+	summary, err := s.itemRepo.GetSummary(scope)
 	if err != nil {
 		return nil, err
 	}
-
-	// Get all transactions count
-	_, totalTransactions, err := s.transactionRepo.GetTransactions(1, 1, "")
+	opds, err := s.opdRepo.GetOPDs()
 	if err != nil {
 		return nil, err
 	}
+	summary.ItemsByOPD = rollUpOPDSummaries(opds, summary.ItemsByOPD)
+	summary.OverdueLoansByOPD = rollUpOPDSummaries(opds, summary.OverdueLoansByOPD)
+	return summary, nil
+}
 
-	summary := &models.DashboardSummary{
-		TotalItems:         int64(len(items)),
-		ItemsInWarehouse:   0,
-		ItemsInOPD:         0,
-		TotalTransactions:  totalTransactions,
-		ItemsByCondition:   make(map[string]int),
-		ItemsByCategory:    []models.CategoryCount{},
-		ItemsByOPD:         []models.OPDCount{},
-	}
-
-	// Count items by location and condition
-	conditionCount := make(map[string]int)
-	categoryCount := make(map[string]int)
-	opdCount := make(map[string]int)
-
-	for _, item := range items {
-		// Count by location
-		if item.CurrentLocation == "Gudang" {
-			summary.ItemsInWarehouse++
-		} else {
-			summary.ItemsInOPD++
-		}
+func (s *DashboardService) GetRecentTransactions(scope models.AccessScope) ([]models.Transaction, error) {
+	return s.transactionRepo.GetRecentTransactions(scope)
+}
 
-		// Count by condition
-		conditionCount[item.Condition]++
+// rollUpOPDSummaries adds the count of every OPD to the total of the OPDs
+// above it. OPDs without items of their own are listed once a unit under
+// them has some.
+func rollUpOPDSummaries(opds []models.OPD, rows []models.OPDSummary) []models.OPDSummary {
+	byID := make(map[uuid.UUID]*models.OPD, len(opds))
+	for i := range opds {
+		byID[opds[i].ID] = &opds[i]
+	}
 
-		// Count by category
-		if item.Category != nil {
-			categoryCount[item.Category.Name]++
+	summaries := make(map[uuid.UUID]*models.OPDSummary)
+	summaryOf := func(id uuid.UUID) *models.OPDSummary {
+		if summary, ok := summaries[id]; ok {
+			return summary
 		}
-
-		// Count by OPD
-		if item.CurrentOPD != nil {
-			opdCount[item.CurrentOPD.Name]++
+		summary := &models.OPDSummary{OPDID: id}
+		if opd, ok := byID[id]; ok {
+			summary.ParentID = opd.ParentID
+			summary.OPDName = opd.Name
 		}
+		summaries[id] = summary
+		return summary
 	}
 
-	summary.ItemsByCondition = conditionCount
-
-	// Convert maps to slices
-	for category, count := range categoryCount {
-		summary.ItemsByCategory = append(summary.ItemsByCategory, models.CategoryCount{
-			CategoryName: category,
-			Count:        count,
-		})
+	for _, row := range rows {
+		own := summaryOf(row.OPDID)
+		own.ParentID, own.OPDName, own.Count = row.ParentID, row.OPDName, row.Count
+		opd, ok := byID[row.OPDID]
+		if !ok {
+			own.TotalCount += row.Count
+			continue
+		}
+		for _, id := range opd.PathIDs() {
+			summaryOf(id).TotalCount += row.Count
+		}
 	}
 
-	for opd, count := range opdCount {
-		summary.ItemsByOPD = append(summary.ItemsByOPD, models.OPDCount{
-			OPDName: opd,
-			Count:   count,
-		})
+	result := make([]models.OPDSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
 	}
-
-	return summary, nil
+	sort.Slice(result, func(i, j int) bool { return result[i].OPDName < result[j].OPDName })
+	return result
 }
-
-func (s *DashboardService) GetRecentTransactions() ([]models.Transaction, error) {
-	return s.transactionRepo.GetRecentTransactions()
-}
\ No newline at end of file
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DisposalService struct {
+	repos             *repositories.Repositories
+	disposalRepo      *repositories.DisposalRepository
+	approvalsRequired int
+}
+
+func NewDisposalService(repos *repositories.Repositories, approvalsRequired int) *DisposalService {
+	return &DisposalService{
+		repos:             repos,
+		disposalRepo:      repos.Disposal,
+		approvalsRequired: approvalsRequired,
+	}
+}
+
+func (s *DisposalService) GetDisposals(params *models.DisposalSearchParams) ([]models.Disposal, int64, error) {
+	return s.disposalRepo.GetDisposals(params)
+}
+
+func (s *DisposalService) GetDisposal(id string) (*models.Disposal, error) {
+	return s.disposalRepo.GetDisposal(id)
+}
+
+// CreateDisposal proposes writing off a set of items. Damaged and lost
+// items must already be recorded as damaged; an item can be on only one
+// open proposal.
+func (s *DisposalService) CreateDisposal(actor models.Actor, req *models.CreateDisposalRequest) (*models.Disposal, error) {
+	if !req.Reason.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDisposalReason, req.Reason)
+	}
+
+	itemIDs := sortedIDs(req.ItemIDs)
+	disposal := &models.Disposal{
+		BaseModel:         models.BaseModel{ID: uuid.New()},
+		Reason:            req.Reason,
+		Status:            models.DisposalProposed,
+		Notes:             req.Notes,
+		ItemCount:         len(itemIDs),
+		ApprovalsRequired: s.approvalsRequired,
+		ProposedBy:        actor.Name,
+		ProposedByID:      actor.ID,
+	}
+
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		pending, err := tx.Disposal.FindPendingItems(itemIDs)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%w: %s", ErrAlreadyProposed, strings.Join(pending, ", "))
+		}
+
+		for _, itemID := range itemIDs {
+			item, err := tx.Item.GetForUpdate(itemID)
+			if err != nil {
+				return fmt.Errorf("item %s: %w", itemID, err)
+			}
+			if err := requireDisposable(tx, item); err != nil {
+				return err
+			}
+			if (req.Reason == models.DisposalDamaged || req.Reason == models.DisposalLost) && item.Condition == models.ConditionGood {
+				return fmt.Errorf("%w: %s is recorded as %s", ErrDisposalCondition, item.SerialNumber, item.Condition)
+			}
+
+			disposal.Items = append(disposal.Items, models.DisposalItem{
+				BaseModel:    models.BaseModel{ID: uuid.New()},
+				ItemID:       item.ID,
+				SerialNumber: item.SerialNumber,
+				Condition:    item.Condition,
+				Location:     item.CurrentLocation,
+				OPDID:        item.CurrentOPDID,
+			})
+		}
+		return tx.Disposal.CreateDisposal(disposal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.disposalRepo.GetDisposal(disposal.ID.String())
+}
+
+// ApproveDisposal records one approval step. The proposal is approved once
+// it has as many approvals, from different admins, as it requires.
+func (s *DisposalService) ApproveDisposal(actor models.Actor, id string, req *models.ApproveDisposalRequest) (*models.Disposal, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		disposal, err := lockProposedDisposal(tx, actor, id)
+		if err != nil {
+			return err
+		}
+
+		if err := createDisposalApproval(tx, disposal, actor, models.DisposalDecisionApprove, req.Comment); err != nil {
+			return err
+		}
+		if len(disposal.Approvals)+1 < disposal.ApprovalsRequired {
+			return nil
+		}
+		disposal.Status = models.DisposalApproved
+		return tx.Disposal.UpdateDisposal(disposal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.disposalRepo.GetDisposal(id)
+}
+
+// RejectDisposal turns down a proposal at any approval step
+func (s *DisposalService) RejectDisposal(actor models.Actor, id string, req *models.RejectDisposalRequest) (*models.Disposal, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		disposal, err := lockProposedDisposal(tx, actor, id)
+		if err != nil {
+			return err
+		}
+
+		if err := createDisposalApproval(tx, disposal, actor, models.DisposalDecisionReject, req.Comment); err != nil {
+			return err
+		}
+		disposal.Status = models.DisposalRejected
+		return tx.Disposal.UpdateDisposal(disposal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.disposalRepo.GetDisposal(id)
+}
+
+// ExecuteDisposal records the decree of an approved proposal and writes its
+// items off. Disposed items keep their history and stay listed under the
+// Dihapuskan location, but can no longer be moved.
+func (s *DisposalService) ExecuteDisposal(actor models.Actor, id string, req *models.ExecuteDisposalRequest) (*models.Disposal, error) {
+	decreeDate, err := parseDateBound(req.DecreeDate, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		disposal, err := tx.Disposal.GetDisposalForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if disposal.Status != models.DisposalApproved {
+			return ErrDisposalNotApproved
+		}
+
+		itemIDs := make([]uuid.UUID, len(disposal.Items))
+		for i, line := range disposal.Items {
+			itemIDs[i] = line.ItemID
+		}
+		for _, itemID := range sortedIDs(itemIDs) {
+			item, err := tx.Item.GetForUpdate(itemID)
+			if err != nil {
+				return fmt.Errorf("item %s: %w", itemID, err)
+			}
+			if err := requireDisposable(tx, item); err != nil {
+				return err
+			}
+			if err := requireOutOfMaintenance(tx, item); err != nil {
+				return err
+			}
+
+			before := *item
+			item.CurrentLocation = models.LocationDisposed
+			item.CurrentOPDID = nil
+			item.CurrentWarehouseID = nil
+			item.CurrentCustodianID = nil
+			item.SpecificLocation = ""
+			item.LocationID = nil
+			item.DisposalID = &disposal.ID
+			item.DisposedAt = &decreeDate
+			if err := tx.Item.Update(item); err != nil {
+				return err
+			}
+			if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &before, item); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		disposal.Status = models.DisposalExecuted
+		disposal.DecreeNumber = req.DecreeNumber
+		disposal.DecreeDate = &decreeDate
+		disposal.ExecutedAt = &now
+		disposal.ExecutedBy = actor.Name
+		disposal.ExecutedByID = actor.ID
+		return tx.Disposal.UpdateDisposal(disposal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.disposalRepo.GetDisposal(id)
+}
+
+// requireDisposable refuses items that are already written off, whose
+// location is about to change or that are out on loan and still to be
+// returned
+func requireDisposable(tx *repositories.Repositories, item *models.Item) error {
+	switch {
+	case !item.IsActive:
+		return fmt.Errorf("%w: %s is deactivated", ErrNotDisposable, item.SerialNumber)
+	case item.CurrentLocation == models.LocationDisposed:
+		return fmt.Errorf("%w: %s is already disposed", ErrNotDisposable, item.SerialNumber)
+	case item.CurrentLocation == models.LocationInTransit:
+		return fmt.Errorf("%w: %s is in transit", ErrNotDisposable, item.SerialNumber)
+	}
+
+	_, err := tx.Transaction.GetOpenLoan(item.ID)
+	if err == nil {
+		return fmt.Errorf("%w: %s is out on loan, record its return first", ErrNotDisposable, item.SerialNumber)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// lockProposedDisposal locks a proposal awaiting review and checks that the
+// actor may review it
+func lockProposedDisposal(tx *repositories.Repositories, actor models.Actor, id string) (*models.Disposal, error) {
+	disposal, err := tx.Disposal.GetDisposalForUpdate(id)
+	if err != nil {
+		return nil, err
+	}
+	if disposal.Status != models.DisposalProposed {
+		return nil, ErrDisposalNotProposed
+	}
+	if actor.ID != nil && disposal.ProposedByID != nil && *actor.ID == *disposal.ProposedByID {
+		return nil, ErrSelfReview
+	}
+	for _, approval := range disposal.Approvals {
+		if actor.ID != nil && approval.ApproverID != nil && *actor.ID == *approval.ApproverID {
+			return nil, ErrAlreadyReviewed
+		}
+	}
+	return disposal, nil
+}
+
+func createDisposalApproval(tx *repositories.Repositories, disposal *models.Disposal, actor models.Actor, decision models.DisposalDecision, comment string) error {
+	return tx.Disposal.CreateApproval(&models.DisposalApproval{
+		BaseModel:  models.BaseModel{ID: uuid.New()},
+		DisposalID: disposal.ID,
+		Step:       len(disposal.Approvals) + 1,
+		Decision:   decision,
+		Comment:    comment,
+		Approver:   actor.Name,
+		ApproverID: actor.ID,
+	})
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"warehouse-system/internal/models"
+)
+
+var (
+	ErrDuplicateSerialNumber    = errors.New("serial number is already registered to another item")
+	ErrInactiveSerialNumber     = errors.New("serial number belongs to a deleted item, restore it instead")
+	ErrInvalidCondition         = errors.New("invalid item condition")
+	ErrInvalidCategory          = errors.New("category does not exist or is inactive")
+	ErrInvalidCredentials       = errors.New("invalid username or password")
+	ErrInvalidToken             = errors.New("invalid or expired token")
+	ErrForbidden                = errors.New("you do not have access to this resource")
+	ErrWarehouseStaffOnly       = errors.New("only warehouse staff can issue items from the warehouse")
+	ErrInvalidRole              = errors.New("invalid user role")
+	ErrOPDRequired              = errors.New("OPD custodians must be assigned to an active OPD")
+	ErrInvalidDirection         = errors.New("invalid transaction direction")
+	ErrMissingOPD               = errors.New("transaction is missing a required OPD")
+	ErrInvalidItemLocation      = errors.New("item is not at the location this direction moves it from")
+	ErrSourceOPDMismatch        = errors.New("source OPD does not match the item's current OPD")
+	ErrInactiveOPD              = errors.New("OPD does not exist or is inactive")
+	ErrAlreadyReversed          = errors.New("transaction has already been reversed")
+	ErrReversalNotReversible    = errors.New("a reversal entry cannot itself be reversed")
+	ErrNotLatestTransaction     = errors.New("only the latest transaction of an item can be reversed")
+	ErrInvalidDate              = errors.New("invalid date, expected YYYY-MM-DD or RFC 3339")
+	ErrImportEmpty              = errors.New("import file has no header row")
+	ErrImportColumnMissing      = errors.New("import file is missing a required column")
+	ErrImportUnknownField       = errors.New("column mapping refers to an unknown field")
+	ErrInvalidLabelFormat       = errors.New("invalid label options")
+	ErrTooManyLabels            = errors.New("too many labels requested")
+	ErrHandoverMixed            = errors.New("transactions on one handover document must share direction, source and target")
+	ErrReversalHandover         = errors.New("reversal entries do not get a handover document")
+	ErrAlreadyDocumented        = errors.New("transaction already has a handover document")
+	ErrHandoverEmpty            = errors.New("a handover document needs either transaction_ids or a batch_id")
+	ErrTransactionInTransit     = errors.New("transaction is still in transit, confirm or reject the receipt instead")
+	ErrTransactionBounced       = errors.New("transaction was rejected or expired in transit")
+	ErrNotInTransit             = errors.New("transaction is not awaiting receipt")
+	ErrReceiverOnly             = errors.New("only the receiving side can confirm or reject a receipt")
+	ErrRequestNotPending        = errors.New("item request is no longer pending")
+	ErrRequestNotApproved       = errors.New("item request is not approved or already fulfilled")
+	ErrApprovedExceedsRequested = errors.New("approved quantity exceeds the requested quantity")
+	ErrUnknownRequestLine       = errors.New("line does not belong to this item request")
+	ErrNothingApproved          = errors.New("approve at least one item or reject the request")
+	ErrRequestItemMismatch      = errors.New("item does not match an outstanding approved category of the request")
+	ErrInvalidLoan              = errors.New("invalid loan")
+	ErrInvalidLoanStatus        = errors.New("invalid loan status, expected open, overdue or returned")
+	ErrItemInMaintenance        = errors.New("item has an open maintenance ticket")
+	ErrTicketClosed             = errors.New("maintenance ticket is already closed")
+	ErrInvalidMaintenanceStatus = errors.New("invalid maintenance ticket status")
+	ErrInvalidDisposalReason    = errors.New("invalid disposal reason, expected damaged, lost, sold or grant")
+	ErrDisposalCondition        = errors.New("damaged or lost items must be recorded as damaged before disposal")
+	ErrNotDisposable            = errors.New("item cannot be disposed")
+	ErrItemDisposed             = errors.New("item is disposed and can no longer be changed")
+	ErrItemNotDeletable         = errors.New("item cannot be deleted while it is in transit, on loan or proposed for disposal")
+	ErrAlreadyProposed          = errors.New("item is already on an open disposal proposal")
+	ErrDisposalNotProposed      = errors.New("disposal proposal is no longer awaiting review")
+	ErrDisposalNotApproved      = errors.New("disposal proposal is not approved")
+	ErrSelfReview               = errors.New("a disposal proposal cannot be reviewed by its proposer")
+	ErrAlreadyReviewed          = errors.New("you have already reviewed this disposal proposal")
+	ErrDuplicateName            = errors.New("name is already used by another active record")
+	ErrNotInTrash               = errors.New("record is not in the trash")
+	ErrStillReferenced          = errors.New("record is still referenced by other records and cannot be purged")
+	ErrInvalidStorageLocation   = errors.New("invalid storage location")
+	ErrInvalidStorageKind       = errors.New("invalid storage location kind")
+	ErrStorageLocationInUse     = errors.New("storage location still has sub-locations, items or history")
+	ErrInvalidOpnameLocation    = errors.New("invalid stock opname location")
+	ErrOpnameAlreadyOpen        = errors.New("this location already has an open stock opname")
+	ErrOpnameClosed             = errors.New("stock opname is closed")
+	ErrOpnameNotClosed          = errors.New("stock opname must be closed before posting corrections")
+	ErrOpnameCorrected          = errors.New("corrections of this stock opname have already been posted")
+	ErrMissingWarehouse         = errors.New("transaction is missing a required warehouse")
+	ErrInactiveWarehouse        = errors.New("warehouse does not exist or is inactive")
+	ErrSourceWarehouseMismatch  = errors.New("source warehouse does not match the item's current warehouse")
+	ErrWarehouseNotEmpty        = errors.New("warehouse still holds items")
+	ErrInvalidOPDParent         = errors.New("an OPD cannot be placed under itself or one of its units")
+	ErrOPDHasSubUnits           = errors.New("OPD still has active sub-units, move or delete them first")
+	ErrInvalidReassignment      = errors.New("invalid reassign_to")
+	ErrStillHasItems            = errors.New("record still has items attached, reassign them first")
+	ErrInvalidReorganization    = errors.New("invalid OPD reorganization")
+	ErrReorganizationBlocked    = errors.New("OPD cannot be reorganized right now")
+	ErrInvalidCustodian         = errors.New("custodian does not exist, is deleted or works in another OPD")
+	ErrDuplicateNIP             = errors.New("NIP is already registered to another active custodian")
+	ErrDefaultWarehouse         = errors.New("the default warehouse cannot be deleted, make another warehouse the default first")
+)
+
+// blockingItemsLimit caps the items listed by a BlockedError
+const blockingItemsLimit = 100
+
+// BlockedError refuses to delete a record that items still point at. It
+// lists the first of them and counts them all. Err and State name another
+// reason the items block the record, such as items in maintenance blocking
+// a reorganization.
+type BlockedError struct {
+	Err   error
+	State string
+	Name  string
+	Items []models.BlockingItem
+	Total int64
+}
+
+func (e *BlockedError) Error() string {
+	msg := fmt.Sprintf("%s: %s has %d items", e.Unwrap(), e.Name, e.Total)
+	if e.State != "" {
+		msg += " " + e.State
+	}
+	return msg
+}
+
+func (e *BlockedError) Unwrap() error {
+	if e.Err != nil {
+		return e.Err
+	}
+	return ErrStillHasItems
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"io"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+	"warehouse-system/pkg/spreadsheet"
+)
+
+var itemExportHeader = []string{
+	"No Seri", "Kategori", "Merek", "Model", "Kondisi", "Keterangan",
+	"Lokasi", "OPD", "Gudang", "Lokasi Spesifik", "Tanggal Masuk", "Tanggal Keluar",
+	"Penanggung Jawab", "NIP Penanggung Jawab",
+}
+
+var transactionExportHeader = []string{
+	"Tanggal", "Arah", "No Seri", "Kategori", "Merek", "Model", "Kondisi",
+	"OPD Asal", "OPD Tujuan", "Gudang Asal", "Gudang Tujuan", "Lokasi Spesifik", "Catatan",
+	"Diproses Oleh", "Status", "Jatuh Tempo Pinjam",
+	"Penanggung Jawab Asal", "Penanggung Jawab Tujuan",
+}
+
+const (
+	exportDateFormat     = "2006-01-02"
+	exportDateTimeFormat = "2006-01-02 15:04"
+)
+
+type ExportService struct {
+	itemRepo        repositories.ItemRepository
+	transactionRepo *repositories.TransactionRepository
+}
+
+func NewExportService(itemRepo repositories.ItemRepository, transactionRepo *repositories.TransactionRepository) *ExportService {
+	return &ExportService{
+		itemRepo:        itemRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// ExportItems writes every item matching the list filters to w
+func (s *ExportService) ExportItems(scope models.AccessScope, params *models.ItemSearchParams, format spreadsheet.Format, w io.Writer) error {
+	writer, err := spreadsheet.NewWriter(w, format, "Barang")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteRow(itemExportHeader); err != nil {
+		return err
+	}
+
+	err = s.itemRepo.StreamAll(scope, params, func(items []models.Item) error {
+		for i := range items {
+			item := &items[i]
+			row := []string{
+				item.SerialNumber,
+				item.Category.Name,
+				item.Brand,
+				item.Model,
+				string(item.Condition),
+				item.Description,
+				string(item.CurrentLocation),
+				opdName(item.CurrentOPD),
+				warehouseName(item.CurrentWarehouse),
+				item.SpecificLocation,
+				formatDate(item.EntryDate, exportDateFormat),
+				formatDate(item.ExitDate, exportDateFormat),
+				custodianName(item.CurrentCustodian),
+				custodianNIP(item.CurrentCustodian),
+			}
+			if err := writer.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// ExportTransactions writes every transaction matching the list filters to w
+func (s *ExportService) ExportTransactions(scope models.AccessScope, params *models.TransactionSearchParams, format spreadsheet.Format, w io.Writer) error {
+	from, to, err := parseDateRange(params.From, params.To)
+	if err != nil {
+		return err
+	}
+
+	writer, err := spreadsheet.NewWriter(w, format, "Transaksi")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteRow(transactionExportHeader); err != nil {
+		return err
+	}
+
+	err = s.transactionRepo.StreamTransactions(scope, params, from, to, func(transactions []models.Transaction) error {
+		for i := range transactions {
+			t := &transactions[i]
+			row := []string{
+				t.TransactionDate.Format(exportDateTimeFormat),
+				string(t.Direction),
+				t.Item.SerialNumber,
+				t.Item.Category.Name,
+				t.Item.Brand,
+				t.Item.Model,
+				string(t.Item.Condition),
+				opdName(t.SourceOPD),
+				opdName(t.TargetOPD),
+				warehouseName(t.SourceWarehouse),
+				warehouseName(t.TargetWarehouse),
+				t.SpecificLocation,
+				t.Notes,
+				t.ProcessedBy,
+				string(t.Status),
+				formatDate(t.LoanDueDate, exportDateFormat),
+				custodianName(t.SourceCustodian),
+				custodianName(t.TargetCustodian),
+			}
+			if err := writer.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func opdName(opd *models.OPD) string {
+	if opd == nil {
+		return ""
+	}
+	return opd.Name
+}
+
+func warehouseName(warehouse *models.Warehouse) string {
+	if warehouse == nil {
+		return ""
+	}
+	return warehouse.Name
+}
+
+func formatDate(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+func custodianName(custodian *models.Custodian) string {
+	if custodian == nil {
+		return ""
+	}
+	return custodian.Name
+}
+
+func custodianNIP(custodian *models.Custodian) string {
+	if custodian == nil {
+		return ""
+	}
+	return custodian.NIP
+}
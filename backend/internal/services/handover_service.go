@@ -0,0 +1,257 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+	"warehouse-system/pkg/handover"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// handoverSequenceKind names the document_sequences counter of BAST numbers
+const handoverSequenceKind = "bast"
+
+type HandoverService struct {
+	repos        *repositories.Repositories
+	handoverRepo *repositories.HandoverRepository
+	templatePath string
+}
+
+func NewHandoverService(repos *repositories.Repositories, templatePath string) *HandoverService {
+	return &HandoverService{
+		repos:        repos,
+		handoverRepo: repos.Handover,
+		templatePath: templatePath,
+	}
+}
+
+// ValidateTemplate loads the configured template so that mistakes surface at
+// startup. The file is read again for every document, so edits apply
+// without a restart.
+func (s *HandoverService) ValidateTemplate() error {
+	_, err := handover.LoadTemplate(s.templatePath)
+	return err
+}
+
+func (s *HandoverService) GetHandoverDocuments(scope models.AccessScope, params *models.HandoverSearchParams) ([]models.HandoverDocument, int64, error) {
+	from, to, err := parseDateRange(params.From, params.To)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.handoverRepo.GetHandoverDocuments(scope, params, from, to)
+}
+
+func (s *HandoverService) GetHandoverDocument(scope models.AccessScope, id string) (*models.HandoverDocument, error) {
+	document, err := s.handoverRepo.GetHandoverDocument(id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsHandover(document) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return document, nil
+}
+
+// CreateHandoverDocument issues a numbered BAST for a group of movements.
+// The movements must share a direction, source and target, since one
+// document has a single giving and receiving party.
+func (s *HandoverService) CreateHandoverDocument(scope models.AccessScope, req *models.CreateHandoverDocumentRequest) (*models.HandoverDocument, error) {
+	tmpl, err := handover.LoadTemplate(s.templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var document *models.HandoverDocument
+	err = s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		ids, err := handoverTransactionIDs(tx, scope, req)
+		if err != nil {
+			return err
+		}
+
+		transactions, err := tx.Transaction.GetTransactionsByIDs(ids)
+		if err != nil {
+			return err
+		}
+		if len(transactions) != len(ids) {
+			return gorm.ErrRecordNotFound
+		}
+
+		first := &transactions[0]
+		for i := range transactions {
+			t := &transactions[i]
+			if !scope.AllowsTransaction(t) {
+				return gorm.ErrRecordNotFound
+			}
+			if t.ReversalOfID != nil {
+				return ErrReversalHandover
+			}
+			if t.Reversal != nil {
+				return ErrAlreadyReversed
+			}
+			if t.Bounced() {
+				return ErrTransactionBounced
+			}
+			if t.Direction != first.Direction || !sameID(t.SourceOPDID, first.SourceOPDID) || !sameID(t.TargetOPDID, first.TargetOPDID) ||
+				!sameID(t.SourceWarehouseID, first.SourceWarehouseID) || !sameID(t.TargetWarehouseID, first.TargetWarehouseID) {
+				return ErrHandoverMixed
+			}
+		}
+
+		documented, err := tx.Handover.GetDocumentedTransactionIDs(ids)
+		if err != nil {
+			return err
+		}
+		if len(documented) > 0 {
+			return ErrAlreadyDocumented
+		}
+
+		// Transactions are ordered by date, the document is dated on the last one
+		last := &transactions[len(transactions)-1]
+		sequence, err := tx.Handover.NextSequence(handoverSequenceKind, last.TransactionDate.Year())
+		if err != nil {
+			return err
+		}
+
+		document = &models.HandoverDocument{
+			BaseModel:         models.BaseModel{ID: uuid.New()},
+			Number:            tmpl.FormatNumber(sequence, last.TransactionDate),
+			Year:              last.TransactionDate.Year(),
+			Sequence:          sequence,
+			Direction:         first.Direction,
+			SourceOPDID:       first.SourceOPDID,
+			TargetOPDID:       first.TargetOPDID,
+			SourceWarehouseID: first.SourceWarehouseID,
+			TargetWarehouseID: first.TargetWarehouseID,
+			SpecificLocation:  commonValue(transactions, func(t *models.Transaction) string { return t.SpecificLocation }),
+			HandoverDate:      last.TransactionDate,
+			ProcessedBy:       commonValue(transactions, func(t *models.Transaction) string { return t.ProcessedBy }),
+			IssuedBy:          req.IssuedBy,
+			IssuedByID:        req.IssuedByID,
+		}
+		for i := range transactions {
+			t := &transactions[i]
+			document.Lines = append(document.Lines, models.HandoverDocumentLine{
+				BaseModel:     models.BaseModel{ID: uuid.New()},
+				TransactionID: t.ID,
+				ItemID:        t.ItemID,
+				SerialNumber:  t.Item.SerialNumber,
+				Brand:         t.Item.Brand,
+				Model:         t.Item.Model,
+				CategoryName:  t.Item.Category.Name,
+				Condition:     t.Item.Condition,
+			})
+		}
+		return tx.Handover.CreateHandoverDocument(document)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.handoverRepo.GetHandoverDocument(document.ID.String())
+}
+
+// handoverTransactionIDs resolves the transactions a document request names
+func handoverTransactionIDs(tx *repositories.Repositories, scope models.AccessScope, req *models.CreateHandoverDocumentRequest) ([]uuid.UUID, error) {
+	switch {
+	case req.BatchID != nil && len(req.TransactionIDs) > 0:
+		return nil, fmt.Errorf("%w: send either transaction_ids or batch_id", ErrHandoverEmpty)
+	case req.BatchID != nil:
+		batch, err := tx.Transfer.GetTransferBatch(req.BatchID.String())
+		if err != nil {
+			return nil, err
+		}
+		if !scope.AllowsTransferBatch(batch) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		ids := make([]uuid.UUID, 0, len(batch.Transactions))
+		for _, t := range batch.Transactions {
+			ids = append(ids, t.ID)
+		}
+		return ids, nil
+	case len(req.TransactionIDs) > 0:
+		return uniqueIDs(req.TransactionIDs), nil
+	}
+	return nil, ErrHandoverEmpty
+}
+
+// WriteHandoverPDF renders a document with the current template
+func (s *HandoverService) WriteHandoverPDF(scope models.AccessScope, id string, w io.Writer) error {
+	document, err := s.GetHandoverDocument(scope, id)
+	if err != nil {
+		return err
+	}
+	tmpl, err := handover.LoadTemplate(s.templatePath)
+	if err != nil {
+		return err
+	}
+
+	doc := handover.Document{
+		Number:   document.Number,
+		Date:     document.HandoverDate,
+		Source:   partyName(document.SourceOPD, document.SourceWarehouse, tmpl),
+		Target:   partyName(document.TargetOPD, document.TargetWarehouse, tmpl),
+		Location: document.SpecificLocation,
+		Officer:  document.ProcessedBy,
+	}
+	for _, line := range document.Lines {
+		doc.Lines = append(doc.Lines, handover.Line{
+			SerialNumber: line.SerialNumber,
+			Name:         strings.TrimSpace(line.Brand + " " + line.Model),
+			Category:     line.CategoryName,
+			Condition:    string(line.Condition),
+		})
+	}
+	return handover.Write(w, tmpl, doc)
+}
+
+// partyName names one side of a handover: the OPD or the warehouse, with
+// the template's warehouse name for documents that do not name one
+func partyName(opd *models.OPD, warehouse *models.Warehouse, tmpl handover.Template) string {
+	switch {
+	case opd != nil:
+		return opd.Name
+	case warehouse != nil:
+		return warehouse.Name
+	}
+	return tmpl.WarehouseName
+}
+
+// sameID reports whether two optional IDs are both empty or equal
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// commonValue returns the value shared by every transaction, or a comma
+// separated list of the distinct values
+func commonValue(transactions []models.Transaction, field func(t *models.Transaction) string) string {
+	var values []string
+	seen := make(map[string]bool)
+	for i := range transactions {
+		value := field(&transactions[i])
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+	return strings.Join(values, ", ")
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	unique := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// importFieldHeaders are the headers recognised for each import field when
+// no explicit mapping is given. Matching ignores case and surrounding spaces.
+var importFieldHeaders = map[string][]string{
+	models.ImportFieldSerialNumber:     {"serial_number", "no seri", "nomor seri", "serial number"},
+	models.ImportFieldCategory:         {"category", "kategori"},
+	models.ImportFieldBrand:            {"brand", "merek", "merk"},
+	models.ImportFieldModel:            {"model", "tipe"},
+	models.ImportFieldCondition:        {"condition", "kondisi"},
+	models.ImportFieldDescription:      {"description", "keterangan", "deskripsi"},
+	models.ImportFieldOPD:              {"opd"},
+	models.ImportFieldSpecificLocation: {"specific_location", "lokasi spesifik"},
+}
+
+var importRequiredFields = []string{
+	models.ImportFieldSerialNumber,
+	models.ImportFieldCategory,
+	models.ImportFieldBrand,
+	models.ImportFieldModel,
+	models.ImportFieldCondition,
+}
+
+type ImportService struct {
+	repos *repositories.Repositories
+}
+
+func NewImportService(repos *repositories.Repositories) *ImportService {
+	return &ImportService{repos: repos}
+}
+
+// importCandidate is a validated row waiting to be inserted
+type importCandidate struct {
+	item  *models.Item
+	opdID *uuid.UUID
+}
+
+// ImportItems validates spreadsheet rows, the first of which is the header.
+// Unless it is a dry run and as long as every row is valid, all items are
+// inserted in one database transaction. Items assigned to an OPD get an
+// initial Gudang → OPD placement transaction.
+func (s *ImportService) ImportItems(actor models.Actor, rows [][]string, opts models.ImportOptions) (*models.ImportReport, error) {
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+
+	columns, err := resolveImportColumns(rows[0], opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repos.Category.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	categoriesByName := make(map[string]models.Category, len(categories))
+	for _, category := range categories {
+		categoriesByName[normalizeHeader(category.Name)] = category
+	}
+
+	opds, err := s.repos.OPD.GetOPDs()
+	if err != nil {
+		return nil, err
+	}
+	opdsByName := make(map[string]models.OPD, len(opds))
+	for _, opd := range opds {
+		opdsByName[normalizeHeader(opd.Name)] = opd
+	}
+
+	report := &models.ImportReport{DryRun: opts.DryRun, Errors: []models.ImportRowError{}}
+	candidates := make([]importCandidate, 0, len(rows)-1)
+	rowsBySerial := make(map[string]int)
+
+	for i, row := range rows[1:] {
+		rowNumber := i + 2
+		if isBlankRow(row) {
+			continue
+		}
+		report.TotalRows++
+
+		cell := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+		fail := func(field, value, message string) {
+			report.Errors = append(report.Errors, models.ImportRowError{Row: rowNumber, Field: field, Value: value, Message: message})
+		}
+		errorsBefore := len(report.Errors)
+
+		for _, field := range importRequiredFields {
+			if cell(field) == "" {
+				fail(field, "", "value is required")
+			}
+		}
+
+		serial := cell(models.ImportFieldSerialNumber)
+		if serial != "" {
+			if firstRow, seen := rowsBySerial[serial]; seen {
+				fail(models.ImportFieldSerialNumber, serial, fmt.Sprintf("duplicates the serial number on row %d", firstRow))
+			} else {
+				rowsBySerial[serial] = rowNumber
+			}
+		}
+
+		var category models.Category
+		if name := cell(models.ImportFieldCategory); name != "" {
+			var ok bool
+			if category, ok = categoriesByName[normalizeHeader(name)]; !ok {
+				fail(models.ImportFieldCategory, name, "unknown or inactive category")
+			}
+		}
+
+		condition, conditionOK := parseCondition(cell(models.ImportFieldCondition))
+		if value := cell(models.ImportFieldCondition); value != "" && !conditionOK {
+			fail(models.ImportFieldCondition, value, "condition must be one of "+conditionList())
+		}
+
+		var opdID *uuid.UUID
+		if name := cell(models.ImportFieldOPD); name != "" {
+			if opd, ok := opdsByName[normalizeHeader(name)]; ok {
+				id := opd.ID
+				opdID = &id
+			} else {
+				fail(models.ImportFieldOPD, name, "unknown or inactive OPD")
+			}
+		}
+
+		if len(report.Errors) > errorsBefore {
+			continue
+		}
+
+		candidates = append(candidates, importCandidate{
+			item: &models.Item{
+				SerialNumber:     serial,
+				CategoryID:       category.ID,
+				Brand:            cell(models.ImportFieldBrand),
+				Model:            cell(models.ImportFieldModel),
+				Condition:        condition,
+				Description:      cell(models.ImportFieldDescription),
+				SpecificLocation: cell(models.ImportFieldSpecificLocation),
+			},
+			opdID: opdID,
+		})
+	}
+
+	// Serial numbers already in the database, including deactivated items
+	serials := make([]string, 0, len(rowsBySerial))
+	for serial := range rowsBySerial {
+		serials = append(serials, serial)
+	}
+	existing, err := s.repos.Item.FindExistingSerialNumbers(serials)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		taken := make(map[string]bool, len(existing))
+		for _, serial := range existing {
+			taken[serial] = true
+			report.Errors = append(report.Errors, models.ImportRowError{
+				Row:     rowsBySerial[serial],
+				Field:   models.ImportFieldSerialNumber,
+				Value:   serial,
+				Message: "serial number is already registered",
+			})
+		}
+		valid := candidates[:0]
+		for _, candidate := range candidates {
+			if !taken[candidate.item.SerialNumber] {
+				valid = append(valid, candidate)
+			}
+		}
+		candidates = valid
+	}
+
+	report.ValidRows = len(candidates)
+	if opts.DryRun || len(report.Errors) > 0 || len(candidates) == 0 {
+		return report, nil
+	}
+
+	err = s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		// Imported items enter the default warehouse
+		warehouseID, err := defaultWarehouseID(tx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, candidate := range candidates {
+			item := candidate.item
+			item.ID = uuid.New()
+			item.EntryDate = &now
+			item.CurrentLocation = models.LocationWarehouse
+			item.CurrentWarehouseID = warehouseID
+			item.IsActive = true
+
+			var placement *models.Transaction
+			if candidate.opdID != nil {
+				placement = &models.Transaction{
+					BaseModel:         models.BaseModel{ID: uuid.New()},
+					ItemID:            item.ID,
+					Direction:         models.DirectionWarehouseToOPD,
+					SourceWarehouseID: warehouseID,
+					TargetOPDID:       candidate.opdID,
+					SpecificLocation:  item.SpecificLocation,
+					Notes:             "Penempatan awal dari impor data",
+					TransactionDate:   now,
+					ProcessedBy:       actor.Name,
+					ProcessedByID:     actor.ID,
+				}
+				applyMovement(item, placement, now)
+			}
+
+			if err := tx.Item.Create(item); err != nil {
+				return fmt.Errorf("serial number %s: %w", item.SerialNumber, err)
+			}
+			if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionCreate, nil, item); err != nil {
+				return err
+			}
+
+			if placement == nil {
+				continue
+			}
+			if err := tx.Transaction.CreateTransaction(placement); err != nil {
+				return err
+			}
+			if err := recordAudit(tx, actor, models.AuditEntityTransaction, placement.ID, models.AuditActionCreate, nil, placement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Committed = true
+	report.ImportedRows = len(candidates)
+	return report, nil
+}
+
+// resolveImportColumns finds the column index of every import field
+func resolveImportColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	indexes := make(map[string]int, len(header))
+	for i, name := range header {
+		key := normalizeHeader(name)
+		if _, exists := indexes[key]; !exists && key != "" {
+			indexes[key] = i
+		}
+	}
+
+	for field := range mapping {
+		if _, known := importFieldHeaders[field]; !known {
+			return nil, fmt.Errorf("%w: %q", ErrImportUnknownField, field)
+		}
+	}
+
+	columns := make(map[string]int)
+	for field, aliases := range importFieldHeaders {
+		if mapped, ok := mapping[field]; ok && mapped != "" {
+			index, found := indexes[normalizeHeader(mapped)]
+			if !found {
+				return nil, fmt.Errorf("%w: %q mapped to %s", ErrImportColumnMissing, mapped, field)
+			}
+			columns[field] = index
+			continue
+		}
+		for _, alias := range aliases {
+			if index, found := indexes[alias]; found {
+				columns[field] = index
+				break
+			}
+		}
+	}
+
+	for _, field := range importRequiredFields {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrImportColumnMissing, field)
+		}
+	}
+	return columns, nil
+}
+
+func normalizeHeader(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCondition matches a condition regardless of letter case
+func parseCondition(value string) (models.Condition, bool) {
+	for _, condition := range models.Conditions {
+		if strings.EqualFold(string(condition), value) {
+			return condition, true
+		}
+	}
+	return "", false
+}
+
+func conditionList() string {
+	names := make([]string, len(models.Conditions))
+	for i, condition := range models.Conditions {
+		names[i] = string(condition)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ItemRequestService struct {
+	repos       *repositories.Repositories
+	requestRepo *repositories.ItemRequestRepository
+	receiptTTL  time.Duration
+}
+
+func NewItemRequestService(repos *repositories.Repositories, receiptTTL time.Duration) *ItemRequestService {
+	return &ItemRequestService{
+		repos:       repos,
+		requestRepo: repos.ItemRequest,
+		receiptTTL:  receiptTTL,
+	}
+}
+
+func (s *ItemRequestService) GetItemRequests(scope models.AccessScope, params *models.ItemRequestSearchParams) ([]models.ItemRequest, int64, error) {
+	return s.requestRepo.GetItemRequests(scope, params)
+}
+
+func (s *ItemRequestService) GetItemRequest(scope models.AccessScope, id string) (*models.ItemRequest, error) {
+	request, err := s.requestRepo.GetItemRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsItemRequest(request) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return request, nil
+}
+
+// CreateItemRequest submits a request for approval. OPD custodians always
+// request for their own OPD.
+func (s *ItemRequestService) CreateItemRequest(scope models.AccessScope, actor models.Actor, req *models.CreateItemRequestRequest) (*models.ItemRequest, error) {
+	opdID := req.OPDID
+	if scope.Restricted {
+		opdID = scope.OPDID
+	}
+	if opdID == nil {
+		return nil, fmt.Errorf("%w: opd_id is required", ErrMissingOPD)
+	}
+
+	request := &models.ItemRequest{
+		BaseModel:     models.BaseModel{ID: uuid.New()},
+		OPDID:         *opdID,
+		Status:        models.ItemRequestPending,
+		Notes:         req.Notes,
+		RequestedBy:   actor.Name,
+		RequestedByID: actor.ID,
+	}
+	for _, line := range req.Lines {
+		request.Lines = append(request.Lines, models.ItemRequestLine{
+			BaseModel:  models.BaseModel{ID: uuid.New()},
+			CategoryID: line.CategoryID,
+			Quantity:   line.Quantity,
+			Notes:      line.Notes,
+		})
+	}
+	request.Events = []models.ItemRequestEvent{newRequestEvent(actor, models.ItemRequestSubmitted, req.Notes)}
+
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		if err := requireActiveOPDs(tx.OPD, opdID); err != nil {
+			return err
+		}
+		for _, line := range req.Lines {
+			category, err := tx.Category.GetCategory(line.CategoryID.String())
+			if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !category.IsActive) {
+				return fmt.Errorf("%w: %s", ErrInvalidCategory, line.CategoryID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return tx.ItemRequest.CreateItemRequest(request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.requestRepo.GetItemRequest(request.ID.String())
+}
+
+// ApproveItemRequest sets the approved quantity of every line of a pending
+// request. Approving less than requested on any line makes the approval
+// partial.
+func (s *ItemRequestService) ApproveItemRequest(actor models.Actor, id string, req *models.ApproveItemRequestRequest) (*models.ItemRequest, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		request, err := lockPendingRequest(tx, models.AccessScope{}, id)
+		if err != nil {
+			return err
+		}
+
+		approved := make(map[uuid.UUID]int, len(req.Lines))
+		for _, line := range req.Lines {
+			approved[line.LineID] = line.ApprovedQuantity
+		}
+
+		partial := false
+		total := 0
+		for i := range request.Lines {
+			line := &request.Lines[i]
+			quantity, ok := approved[line.ID]
+			if !ok {
+				quantity = line.Quantity
+			}
+			delete(approved, line.ID)
+			if quantity > line.Quantity {
+				return fmt.Errorf("%w: line %s", ErrApprovedExceedsRequested, line.ID)
+			}
+			if quantity < line.Quantity {
+				partial = true
+			}
+			total += quantity
+
+			line.ApprovedQuantity = quantity
+			if err := tx.ItemRequest.UpdateLine(line); err != nil {
+				return err
+			}
+		}
+		for lineID := range approved {
+			return fmt.Errorf("%w: %s", ErrUnknownRequestLine, lineID)
+		}
+		if total == 0 {
+			return ErrNothingApproved
+		}
+
+		request.Status = models.ItemRequestApproved
+		action := models.ItemRequestActionApprove
+		if partial {
+			request.Status = models.ItemRequestPartiallyApproved
+			action = models.ItemRequestActionPartial
+		}
+		if err := tx.ItemRequest.UpdateItemRequest(request); err != nil {
+			return err
+		}
+		return createRequestEvent(tx, request, actor, action, req.Comment, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.requestRepo.GetItemRequest(id)
+}
+
+// RejectItemRequest turns down a pending request
+func (s *ItemRequestService) RejectItemRequest(actor models.Actor, id string, comment string) (*models.ItemRequest, error) {
+	return s.closePending(models.AccessScope{}, actor, id, models.ItemRequestRejected, models.ItemRequestActionReject, comment)
+}
+
+// CancelItemRequest withdraws a pending request on behalf of its OPD
+func (s *ItemRequestService) CancelItemRequest(scope models.AccessScope, actor models.Actor, id string, comment string) (*models.ItemRequest, error) {
+	return s.closePending(scope, actor, id, models.ItemRequestCancelled, models.ItemRequestActionCancel, comment)
+}
+
+func (s *ItemRequestService) closePending(scope models.AccessScope, actor models.Actor, id string, status models.ItemRequestStatus, action models.ItemRequestAction, comment string) (*models.ItemRequest, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		request, err := lockPendingRequest(tx, scope, id)
+		if err != nil {
+			return err
+		}
+		request.Status = status
+		if err := tx.ItemRequest.UpdateItemRequest(request); err != nil {
+			return err
+		}
+		return createRequestEvent(tx, request, actor, action, comment, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.requestRepo.GetItemRequest(id)
+}
+
+// FulfillItemRequest delivers items of an approved request from the
+// warehouse as one transfer batch. Every item must match an outstanding
+// approved quantity of its category; a request can be delivered in parts.
+func (s *ItemRequestService) FulfillItemRequest(actor models.Actor, id string, req *models.FulfillItemRequestRequest) (*models.ItemRequest, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		request, err := tx.ItemRequest.GetItemRequestForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if request.Status != models.ItemRequestApproved && request.Status != models.ItemRequestPartiallyApproved {
+			return ErrRequestNotApproved
+		}
+
+		itemIDs := sortedIDs(req.ItemIDs)
+		for _, itemID := range itemIDs {
+			item, err := tx.Item.GetForUpdate(itemID)
+			if err != nil {
+				return fmt.Errorf("item %s: %w", itemID, err)
+			}
+			if !allocateToLine(request.Lines, item.CategoryID) {
+				return fmt.Errorf("%w: %s", ErrRequestItemMismatch, item.SerialNumber)
+			}
+		}
+
+		batch, err := recordTransferBatch(tx, models.AccessScope{}, &models.CreateTransferBatchRequest{
+			ItemIDs:          itemIDs,
+			Direction:        models.DirectionWarehouseToOPD,
+			TargetOPDID:      &request.OPDID,
+			SpecificLocation: req.SpecificLocation,
+			LocationID:       req.LocationID,
+			Notes:            req.Notes,
+			RequireReceipt:   req.RequireReceipt,
+			RequestID:        &request.ID,
+			ProcessedBy:      actor.Name,
+			ProcessedByID:    actor.ID,
+		}, s.receiptTTL)
+		if err != nil {
+			return err
+		}
+
+		outstanding := 0
+		for i := range request.Lines {
+			line := &request.Lines[i]
+			if err := tx.ItemRequest.UpdateLine(line); err != nil {
+				return err
+			}
+			outstanding += line.Outstanding()
+		}
+		comment := fmt.Sprintf("%d items delivered", len(itemIDs))
+		if err := createRequestEvent(tx, request, actor, models.ItemRequestActionDeliver, comment, &batch.ID); err != nil {
+			return err
+		}
+
+		if outstanding > 0 {
+			return nil
+		}
+		request.Status = models.ItemRequestFulfilled
+		if err := tx.ItemRequest.UpdateItemRequest(request); err != nil {
+			return err
+		}
+		return createRequestEvent(tx, request, actor, models.ItemRequestActionFulfillment, "", nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.requestRepo.GetItemRequest(id)
+}
+
+// allocateToLine counts an item against the first line of its category
+// that still has an outstanding approved quantity
+func allocateToLine(lines []models.ItemRequestLine, categoryID uuid.UUID) bool {
+	for i := range lines {
+		line := &lines[i]
+		if line.CategoryID == categoryID && line.Outstanding() > 0 {
+			line.DeliveredQuantity++
+			return true
+		}
+	}
+	return false
+}
+
+// returnToRequest takes an item delivered for a request off its line again
+// when the delivery bounced or was reversed. A fulfilled request reopens so
+// the item can be delivered anew.
+func returnToRequest(tx *repositories.Repositories, actor models.Actor, transaction *models.Transaction, item *models.Item, reason string) error {
+	if transaction.RequestID == nil {
+		return nil
+	}
+	request, err := tx.ItemRequest.GetItemRequestForUpdate(transaction.RequestID.String())
+	if err != nil {
+		return err
+	}
+
+	line := deliveredLine(request.Lines, item.CategoryID)
+	if line == nil {
+		return nil
+	}
+	line.DeliveredQuantity--
+	if err := tx.ItemRequest.UpdateLine(line); err != nil {
+		return err
+	}
+
+	if request.Status == models.ItemRequestFulfilled {
+		request.Status = models.ItemRequestApproved
+		for i := range request.Lines {
+			if request.Lines[i].ApprovedQuantity < request.Lines[i].Quantity {
+				request.Status = models.ItemRequestPartiallyApproved
+			}
+		}
+		if err := tx.ItemRequest.UpdateItemRequest(request); err != nil {
+			return err
+		}
+	}
+	comment := fmt.Sprintf("%s returned: %s", item.SerialNumber, reason)
+	return createRequestEvent(tx, request, actor, models.ItemRequestActionReturn, comment, transaction.BatchID)
+}
+
+// deliveredLine finds the line an item was counted against: the last line of
+// its category with deliveries, or any line with deliveries when the item
+// has been recategorised since
+func deliveredLine(lines []models.ItemRequestLine, categoryID uuid.UUID) *models.ItemRequestLine {
+	var fallback *models.ItemRequestLine
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := &lines[i]
+		if line.DeliveredQuantity == 0 {
+			continue
+		}
+		if line.CategoryID == categoryID {
+			return line
+		}
+		if fallback == nil {
+			fallback = line
+		}
+	}
+	return fallback
+}
+
+func lockPendingRequest(tx *repositories.Repositories, scope models.AccessScope, id string) (*models.ItemRequest, error) {
+	request, err := tx.ItemRequest.GetItemRequestForUpdate(id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsItemRequest(request) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if request.Status != models.ItemRequestPending {
+		return nil, ErrRequestNotPending
+	}
+	return request, nil
+}
+
+func newRequestEvent(actor models.Actor, action models.ItemRequestAction, comment string) models.ItemRequestEvent {
+	return models.ItemRequestEvent{
+		BaseModel: models.BaseModel{ID: uuid.New()},
+		Action:    action,
+		Comment:   comment,
+		Actor:     actor.Name,
+		ActorID:   actor.ID,
+	}
+}
+
+func createRequestEvent(tx *repositories.Repositories, request *models.ItemRequest, actor models.Actor, action models.ItemRequestAction, comment string, batchID *uuid.UUID) error {
+	event := newRequestEvent(actor, action, comment)
+	event.RequestID = request.ID
+	event.BatchID = batchID
+	return tx.ItemRequest.CreateEvent(&event)
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ItemService struct {
+	repos           *repositories.Repositories
+	itemRepo        repositories.ItemRepository
+	transactionRepo *repositories.TransactionRepository
+	categoryRepo    *repositories.CategoryRepository
+}
+
+func NewItemService(repos *repositories.Repositories) *ItemService {
+	return &ItemService{
+		repos:           repos,
+		itemRepo:        repos.Item,
+		transactionRepo: repos.Transaction,
+		categoryRepo:    repos.Category,
+	}
+}
+
+func (s *ItemService) GetItems(scope models.AccessScope, params *models.ItemSearchParams) ([]models.Item, int64, error) {
+	return s.itemRepo.GetAll(scope, params)
+}
+
+func (s *ItemService) GetItem(scope models.AccessScope, id uuid.UUID) (*models.Item, error) {
+	item, err := s.itemRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsItem(item) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return item, nil
+}
+
+func (s *ItemService) CreateItem(actor models.Actor, req *models.CreateItemRequest) (*models.Item, error) {
+	if err := s.validateItem(uuid.Nil, req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	item := &models.Item{
+		BaseModel:        models.BaseModel{ID: uuid.New()},
+		SerialNumber:     req.SerialNumber,
+		CategoryID:       req.CategoryID,
+		Brand:            req.Brand,
+		Model:            req.Model,
+		Condition:        req.Condition,
+		Description:      req.Description,
+		EntryDate:        &now,
+		CurrentLocation:  models.LocationWarehouse,
+		SpecificLocation: req.SpecificLocation,
+		SoftDelete:       models.Active(),
+	}
+
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		item.CurrentWarehouseID = req.WarehouseID
+		if item.CurrentWarehouseID == nil {
+			warehouseID, err := defaultWarehouseID(tx)
+			if err != nil {
+				return err
+			}
+			item.CurrentWarehouseID = warehouseID
+		}
+		if err := requireActiveWarehouses(tx.Warehouse, item.CurrentWarehouseID); err != nil {
+			return err
+		}
+		if req.LocationID != nil {
+			if err := placeNewItem(tx, item, *req.LocationID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Item.Create(item); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionCreate, nil, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.itemRepo.GetByID(item.ID)
+}
+
+// UpdateItem edits the details of an item. Disposed items are kept as they
+// were written off.
+func (s *ItemService) UpdateItem(actor models.Actor, id uuid.UUID, req *models.CreateItemRequest) (*models.Item, error) {
+	if err := s.validateItem(id, req); err != nil {
+		return nil, err
+	}
+
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		item, err := tx.Item.GetForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if item.CurrentLocation == models.LocationDisposed {
+			return fmt.Errorf("%w: %s", ErrItemDisposed, item.SerialNumber)
+		}
+		before := *item
+
+		item.SerialNumber = req.SerialNumber
+		item.CategoryID = req.CategoryID
+		item.Brand = req.Brand
+		item.Model = req.Model
+		item.Condition = req.Condition
+		item.Description = req.Description
+		// Items placed at a storage location are moved, not edited
+		if item.LocationID == nil {
+			item.SpecificLocation = req.SpecificLocation
+		}
+
+		if err := tx.Item.Update(item); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityItem, id, models.AuditActionUpdate, &before, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.itemRepo.GetByID(id)
+}
+
+// DeleteItem moves an item to the trash. Items whose movement, loan,
+// disposal or maintenance is still under way stay until it is settled.
+func (s *ItemService) DeleteItem(actor models.Actor, id uuid.UUID) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		item, err := tx.Item.GetForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := requireDeletable(tx, item); err != nil {
+			return err
+		}
+		before := *item
+
+		item.MarkDeleted(actor, time.Now())
+		if err := tx.Item.Update(item); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityItem, id, models.AuditActionDelete, &before, item)
+	})
+}
+
+// requireDeletable refuses to trash a locked item that is in transit, out
+// on loan, on an open disposal proposal or under maintenance
+func requireDeletable(tx *repositories.Repositories, item *models.Item) error {
+	if item.CurrentLocation == models.LocationInTransit {
+		return fmt.Errorf("%w: %s is in transit", ErrItemNotDeletable, item.SerialNumber)
+	}
+
+	_, err := tx.Transaction.GetOpenLoan(item.ID)
+	if err == nil {
+		return fmt.Errorf("%w: %s is out on loan", ErrItemNotDeletable, item.SerialNumber)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	pending, err := tx.Disposal.FindPendingItems([]uuid.UUID{item.ID})
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s is on an open disposal proposal", ErrItemNotDeletable, item.SerialNumber)
+	}
+
+	return requireOutOfMaintenance(tx, item)
+}
+
+func (s *ItemService) GetDeletedItems(params *models.TrashSearchParams) ([]models.Item, int64, error) {
+	return s.itemRepo.GetDeleted(params)
+}
+
+// RestoreItem takes an item out of the trash. Its category, and the OPD
+// holding it, must be active again first.
+func (s *ItemService) RestoreItem(actor models.Actor, id uuid.UUID) (*models.Item, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		item, err := tx.Item.GetDeletedForUpdate(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if _, activeErr := tx.Item.GetByID(id); activeErr == nil {
+					return ErrNotInTrash
+				}
+			}
+			return err
+		}
+
+		category, err := tx.Category.GetCategory(item.CategoryID.String())
+		if err != nil {
+			return err
+		}
+		if !category.IsActive {
+			return fmt.Errorf("%w: restore category %s first", ErrInvalidCategory, category.Name)
+		}
+		if item.CurrentLocation == models.LocationOPD {
+			if err := requireActiveOPDs(tx.OPD, item.CurrentOPDID); err != nil {
+				return err
+			}
+		}
+		if item.CurrentLocation == models.LocationWarehouse {
+			if err := requireActiveWarehouses(tx.Warehouse, item.CurrentWarehouseID); err != nil {
+				return err
+			}
+		}
+
+		before := *item
+		item.Restore()
+		// A custodian who left the OPD meanwhile is no longer responsible
+		item.CurrentCustodianID, err = custodianIn(tx, item.CurrentCustodianID, item.CurrentOPDID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Item.Update(item); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityItem, id, models.AuditActionRestore, &before, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.itemRepo.GetByID(id)
+}
+
+// PurgeItem permanently removes an item from the trash. Items with any
+// recorded history cannot be purged.
+func (s *ItemService) PurgeItem(actor models.Actor, id uuid.UUID) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		item, err := tx.Item.GetDeletedForUpdate(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if _, activeErr := tx.Item.GetByID(id); activeErr == nil {
+					return ErrNotInTrash
+				}
+			}
+			return err
+		}
+		referenced, err := tx.Item.IsReferenced(id)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return ErrStillReferenced
+		}
+
+		if err := tx.Item.Purge(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityItem, id, models.AuditActionPurge, item, nil)
+	})
+}
+
+// SearchItems resolves an exact serial number or item ID match first, which
+// is what label scanners send, and falls back to a free-text search otherwise
+func (s *ItemService) SearchItems(scope models.AccessScope, query string) ([]models.Item, error) {
+	if id, err := uuid.Parse(query); err == nil {
+		item, err := s.GetItem(scope, id)
+		if err == nil {
+			return []models.Item{*item}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	item, err := s.itemRepo.GetBySerialNumber(query)
+	if err == nil && scope.AllowsItem(item) {
+		return []models.Item{*item}, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	items, _, err := s.itemRepo.GetAll(scope, &models.ItemSearchParams{Query: query, Page: 1, Limit: 50})
+	return items, err
+}
+
+// validateItem checks the request against the master data. excludeID is the
+// item being updated, or uuid.Nil when creating a new one.
+func (s *ItemService) validateItem(excludeID uuid.UUID, req *models.CreateItemRequest) error {
+	if !req.Condition.IsValid() {
+		return ErrInvalidCondition
+	}
+
+	category, err := s.categoryRepo.GetCategory(req.CategoryID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidCategory
+		}
+		return err
+	}
+	if !category.IsActive {
+		return ErrInvalidCategory
+	}
+
+	existing, err := s.itemRepo.FindBySerialNumber(req.SerialNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID == excludeID {
+		return nil
+	}
+	if !existing.IsActive {
+		return ErrInactiveSerialNumber
+	}
+	return ErrDuplicateSerialNumber
+}
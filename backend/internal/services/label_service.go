@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+	"warehouse-system/pkg/label"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxSheetLabels caps a single PDF sheet request, the whole document is
+// built in memory before it is sent
+const maxSheetLabels = 2000
+
+type LabelService struct {
+	itemRepo    repositories.ItemRepository
+	urlTemplate string
+}
+
+func NewLabelService(itemRepo repositories.ItemRepository, urlTemplate string) *LabelService {
+	return &LabelService{
+		itemRepo:    itemRepo,
+		urlTemplate: urlTemplate,
+	}
+}
+
+// WriteItemLabel renders the label of a single item as PNG or SVG
+func (s *LabelService) WriteItemLabel(scope models.AccessScope, id uuid.UUID, params *models.LabelParams, w io.Writer) error {
+	symbology, err := s.symbology(params)
+	if err != nil {
+		return err
+	}
+
+	item, err := s.itemRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !scope.AllowsItem(item) {
+		return gorm.ErrRecordNotFound
+	}
+
+	lbl, err := s.itemLabel(item, params.Content, symbology)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(params.Format) {
+	case "", "png":
+		return label.WritePNG(w, lbl, symbology)
+	case "svg":
+		return label.WriteSVG(w, lbl, symbology)
+	}
+	return ErrInvalidLabelFormat
+}
+
+// WriteLabelSheet renders A4 label sheets for every item matching the list
+// filters
+func (s *LabelService) WriteLabelSheet(scope models.AccessScope, search *models.ItemSearchParams, params *models.LabelParams, w io.Writer) error {
+	symbology, err := s.symbology(params)
+	if err != nil {
+		return err
+	}
+
+	var labels []label.Label
+	err = s.itemRepo.StreamAll(scope, search, func(items []models.Item) error {
+		for i := range items {
+			if len(labels) >= maxSheetLabels {
+				return fmt.Errorf("%w: at most %d labels per sheet, narrow the filters", ErrTooManyLabels, maxSheetLabels)
+			}
+			lbl, err := s.itemLabel(&items[i], params.Content, symbology)
+			if err != nil {
+				return err
+			}
+			labels = append(labels, lbl)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return label.WritePDFSheet(w, labels, symbology)
+}
+
+func (s *LabelService) symbology(params *models.LabelParams) (label.Symbology, error) {
+	if params.Type == "" {
+		return label.SymbologyQR, nil
+	}
+	symbology, err := label.ParseSymbology(params.Type)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidLabelFormat, err)
+	}
+	return symbology, nil
+}
+
+// itemLabel builds the label text for an item. The serial number is the
+// default code because /items/search resolves it with an exact match; QR
+// codes can additionally be wrapped in a URL so phone scanners open the
+// item directly.
+func (s *LabelService) itemLabel(item *models.Item, content models.LabelContent, symbology label.Symbology) (label.Label, error) {
+	var code string
+	switch content {
+	case "", models.LabelContentSerial:
+		code = item.SerialNumber
+	case models.LabelContentID:
+		code = item.ID.String()
+	default:
+		return label.Label{}, fmt.Errorf("%w: content must be serial or id", ErrInvalidLabelFormat)
+	}
+
+	encoded := code
+	if symbology == label.SymbologyQR && s.urlTemplate != "" {
+		encoded = strings.ReplaceAll(s.urlTemplate, "{code}", code)
+	}
+
+	return label.Label{
+		Content: encoded,
+		Caption: code,
+		Lines: []string{
+			strings.TrimSpace(item.Brand + " " + item.Model),
+			item.Category.Name,
+		},
+	}, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+type LoanService struct {
+	transactionRepo *repositories.TransactionRepository
+}
+
+func NewLoanService(transactionRepo *repositories.TransactionRepository) *LoanService {
+	return &LoanService{transactionRepo: transactionRepo}
+}
+
+// GetLoans lists loans by status, open loans by default
+func (s *LoanService) GetLoans(scope models.AccessScope, params *models.LoanSearchParams) ([]models.Transaction, int64, error) {
+	if params.Status == "" {
+		params.Status = models.LoanOpen
+	}
+	if !params.Status.IsValid() {
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalidLoanStatus, params.Status)
+	}
+	return s.transactionRepo.GetLoans(scope, params, time.Now())
+}
+
+// parseLoanDueDate reads the due date of a new loan. A plain date means
+// the end of that day; an empty value means the movement is not a loan.
+func parseLoanDueDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	due, err := parseDateBound(value, true)
+	if err != nil {
+		return nil, err
+	}
+	return &due, nil
+}
+
+// validateLoan checks the due date of a transaction about to be recorded
+func validateLoan(t *models.Transaction) error {
+	if t.LoanDueDate == nil {
+		return nil
+	}
+	if t.Direction != models.DirectionWarehouseToOPD {
+		return fmt.Errorf("%w: only items issued from the warehouse can be lent", ErrInvalidLoan)
+	}
+	if !t.LoanDueDate.After(t.TransactionDate) {
+		return fmt.Errorf("%w: loan_due_date must be after the transaction date", ErrInvalidLoan)
+	}
+	return nil
+}
+
+// settleLoan closes the open loan of an item that has just come back to
+// the warehouse through transaction returned
+func settleLoan(tx *repositories.Repositories, actor models.Actor, returned *models.Transaction) error {
+	loan, err := tx.Transaction.GetOpenLoan(returned.ItemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	before := *loan
+	at := returned.TransactionDate
+	if returned.ReceiptAt != nil {
+		at = *returned.ReceiptAt
+	}
+	loan.LoanReturnedAt = &at
+	loan.LoanReturnID = &returned.ID
+	if err := tx.Transaction.UpdateLoanReturn(loan); err != nil {
+		return err
+	}
+	return recordAudit(tx, actor, models.AuditEntityTransaction, loan.ID, models.AuditActionUpdate, &before, loan)
+}
+
+// reopenLoan undoes settleLoan when the return is reversed and the item
+// goes back out
+func reopenLoan(tx *repositories.Repositories, actor models.Actor, returned *models.Transaction) error {
+	loan, err := tx.Transaction.GetLoanReturnedBy(returned.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	before := *loan
+	loan.LoanReturnedAt = nil
+	loan.LoanReturnID = nil
+	if err := tx.Transaction.UpdateLoanReturn(loan); err != nil {
+		return err
+	}
+	return recordAudit(tx, actor, models.AuditEntityTransaction, loan.ID, models.AuditActionUpdate, &before, loan)
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MaintenanceService struct {
+	repos           *repositories.Repositories
+	maintenanceRepo *repositories.MaintenanceRepository
+}
+
+func NewMaintenanceService(repos *repositories.Repositories) *MaintenanceService {
+	return &MaintenanceService{
+		repos:           repos,
+		maintenanceRepo: repos.Maintenance,
+	}
+}
+
+func (s *MaintenanceService) GetMaintenanceTickets(scope models.AccessScope, params *models.MaintenanceSearchParams) ([]models.MaintenanceTicket, int64, error) {
+	return s.maintenanceRepo.GetMaintenanceTickets(scope, params)
+}
+
+func (s *MaintenanceService) GetMaintenanceTicket(scope models.AccessScope, id string) (*models.MaintenanceTicket, error) {
+	ticket, err := s.maintenanceRepo.GetMaintenanceTicket(id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsMaintenanceTicket(ticket) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return ticket, nil
+}
+
+// CreateMaintenanceTicket reports an issue with an item. The item stays
+// where it is but cannot be moved until the ticket is closed.
+func (s *MaintenanceService) CreateMaintenanceTicket(scope models.AccessScope, actor models.Actor, req *models.CreateMaintenanceTicketRequest) (*models.MaintenanceTicket, error) {
+	if req.Condition != "" && !req.Condition.IsValid() {
+		return nil, ErrInvalidCondition
+	}
+
+	var ticket *models.MaintenanceTicket
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		// Lock the item so a movement cannot slip in while the ticket opens
+		item, err := tx.Item.GetForUpdate(req.ItemID)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsItem(item) {
+			return ErrForbidden
+		}
+		switch item.CurrentLocation {
+		case models.LocationInTransit:
+			return fmt.Errorf("%w: item is in transit", ErrInvalidItemLocation)
+		case models.LocationDisposed:
+			return fmt.Errorf("%w: item is disposed", ErrInvalidItemLocation)
+		}
+		if err := requireOutOfMaintenance(tx, item); err != nil {
+			return err
+		}
+
+		ticket = &models.MaintenanceTicket{
+			BaseModel:       models.BaseModel{ID: uuid.New()},
+			ItemID:          item.ID,
+			OPDID:           item.CurrentOPDID,
+			Status:          models.MaintenanceReported,
+			Issue:           req.Issue,
+			ConditionBefore: item.Condition,
+			Vendor:          req.Vendor,
+			ReportedBy:      actor.Name,
+			ReportedByID:    actor.ID,
+		}
+		if err := tx.Maintenance.CreateMaintenanceTicket(ticket); err != nil {
+			return err
+		}
+
+		if req.Condition != "" && req.Condition != item.Condition {
+			return updateCondition(tx, actor, item, req.Condition)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.maintenanceRepo.GetMaintenanceTicket(ticket.ID.String())
+}
+
+// UpdateMaintenanceTicket records vendor, cost and notes of an open ticket
+// and moves a reported ticket into repair
+func (s *MaintenanceService) UpdateMaintenanceTicket(id string, req *models.UpdateMaintenanceTicketRequest) (*models.MaintenanceTicket, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		ticket, _, err := lockOpenTicket(tx, id)
+		if err != nil {
+			return err
+		}
+
+		switch req.Status {
+		case "", ticket.Status:
+		case models.MaintenanceInRepair:
+			now := time.Now()
+			ticket.Status = models.MaintenanceInRepair
+			ticket.RepairStartedAt = &now
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidMaintenanceStatus, req.Status)
+		}
+		if req.Vendor != nil {
+			ticket.Vendor = *req.Vendor
+		}
+		if req.Cost != nil {
+			ticket.Cost = *req.Cost
+		}
+		if req.Notes != nil {
+			ticket.Notes = *req.Notes
+		}
+		return tx.Maintenance.UpdateMaintenanceTicket(ticket)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.maintenanceRepo.GetMaintenanceTicket(id)
+}
+
+// CloseMaintenanceTicket finishes a ticket as repaired or unrepairable,
+// sets the item's condition accordingly and releases the item
+func (s *MaintenanceService) CloseMaintenanceTicket(actor models.Actor, id string, req *models.CloseMaintenanceTicketRequest) (*models.MaintenanceTicket, error) {
+	condition, err := closingCondition(req.Status, req.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		ticket, item, err := lockOpenTicket(tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		ticket.Status = req.Status
+		ticket.ConditionAfter = condition
+		ticket.Resolution = req.Resolution
+		ticket.ClosedAt = &now
+		ticket.ClosedBy = actor.Name
+		ticket.ClosedByID = actor.ID
+		if req.Cost != nil {
+			ticket.Cost = *req.Cost
+		}
+		if err := tx.Maintenance.UpdateMaintenanceTicket(ticket); err != nil {
+			return err
+		}
+
+		if item.Condition == condition {
+			return nil
+		}
+		return updateCondition(tx, actor, item, condition)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.maintenanceRepo.GetMaintenanceTicket(id)
+}
+
+// closingCondition is the condition an item ends up in when its ticket is
+// closed with the given status
+func closingCondition(status models.MaintenanceStatus, requested models.Condition) (models.Condition, error) {
+	switch status {
+	case models.MaintenanceRepaired:
+		if requested == "" {
+			return models.ConditionGood, nil
+		}
+		if !requested.IsValid() {
+			return "", ErrInvalidCondition
+		}
+		return requested, nil
+	case models.MaintenanceUnrepairable:
+		if requested != "" && requested != models.ConditionBroken {
+			return "", fmt.Errorf("%w: an unrepairable item is %s", ErrInvalidCondition, models.ConditionBroken)
+		}
+		return models.ConditionBroken, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidMaintenanceStatus, status)
+	}
+}
+
+// lockOpenTicket locks the item of a ticket and makes sure the ticket is
+// still open. The ticket is read after the lock so a concurrent close is seen.
+// Tickets of items moved to the trash can still be worked on and closed.
+func lockOpenTicket(tx *repositories.Repositories, id string) (*models.MaintenanceTicket, *models.Item, error) {
+	ticket, err := tx.Maintenance.GetMaintenanceTicket(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	item, err := tx.Item.GetForUpdate(ticket.ItemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		item, err = tx.Item.GetDeletedForUpdate(ticket.ItemID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	ticket, err = tx.Maintenance.GetMaintenanceTicket(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ticket.Status.IsOpen() {
+		return nil, nil, ErrTicketClosed
+	}
+	return ticket, item, nil
+}
+
+// requireOutOfMaintenance refuses to move a locked item with an open
+// maintenance ticket
+func requireOutOfMaintenance(tx *repositories.Repositories, item *models.Item) error {
+	_, err := tx.Maintenance.FindOpenTicket(item.ID)
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrItemInMaintenance, item.SerialNumber)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// validateMovement checks the direction of transaction t against where the
+// item currently is and makes sure the OPDs and warehouses the direction
+// needs, and only those, are present
+func validateMovement(item *models.Item, t *models.Transaction) error {
+	switch t.Direction {
+	case models.DirectionWarehouseToOPD:
+		if err := validateSourceWarehouse(item, t.SourceWarehouseID); err != nil {
+			return err
+		}
+		if t.TargetOPDID == nil {
+			return fmt.Errorf("%w: target_opd_id is required", ErrMissingOPD)
+		}
+		if t.SourceOPDID != nil {
+			return fmt.Errorf("%w: source_opd_id must be empty when issuing from the warehouse", ErrInvalidDirection)
+		}
+	case models.DirectionOPDToWarehouse:
+		if err := validateSourceOPD(item, t.SourceOPDID); err != nil {
+			return err
+		}
+		if t.TargetOPDID != nil {
+			return fmt.Errorf("%w: target_opd_id must be empty when returning to the warehouse", ErrInvalidDirection)
+		}
+		if t.TargetWarehouseID == nil {
+			return fmt.Errorf("%w: target_warehouse_id is required", ErrMissingWarehouse)
+		}
+	case models.DirectionOPDToOPD:
+		if err := validateSourceOPD(item, t.SourceOPDID); err != nil {
+			return err
+		}
+		if t.TargetOPDID == nil {
+			return fmt.Errorf("%w: target_opd_id is required", ErrMissingOPD)
+		}
+		if *t.TargetOPDID == *t.SourceOPDID {
+			return fmt.Errorf("%w: source and target OPD are the same", ErrInvalidDirection)
+		}
+	case models.DirectionWarehouseToWarehouse:
+		if err := validateSourceWarehouse(item, t.SourceWarehouseID); err != nil {
+			return err
+		}
+		if t.SourceOPDID != nil || t.TargetOPDID != nil {
+			return fmt.Errorf("%w: OPDs must be empty when moving between warehouses", ErrInvalidDirection)
+		}
+		if t.TargetWarehouseID == nil {
+			return fmt.Errorf("%w: target_warehouse_id is required", ErrMissingWarehouse)
+		}
+		if *t.TargetWarehouseID == *t.SourceWarehouseID {
+			return fmt.Errorf("%w: source and target warehouse are the same", ErrInvalidDirection)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidDirection, t.Direction)
+	}
+
+	if t.SourceWarehouseID != nil && !t.Direction.FromWarehouse() {
+		return fmt.Errorf("%w: source_warehouse_id must be empty when the item leaves an OPD", ErrInvalidDirection)
+	}
+	if t.TargetWarehouseID != nil && !t.Direction.ToWarehouse() {
+		return fmt.Errorf("%w: target_warehouse_id must be empty when the item goes to an OPD", ErrInvalidDirection)
+	}
+	return nil
+}
+
+func validateSourceWarehouse(item *models.Item, sourceWarehouseID *uuid.UUID) error {
+	if item.CurrentLocation != models.LocationWarehouse {
+		return fmt.Errorf("%w: item is not in a warehouse", ErrInvalidItemLocation)
+	}
+	if sourceWarehouseID == nil {
+		return fmt.Errorf("%w: source_warehouse_id is required", ErrMissingWarehouse)
+	}
+	if !sameID(sourceWarehouseID, item.CurrentWarehouseID) {
+		return fmt.Errorf("%w: item is in another warehouse", ErrSourceWarehouseMismatch)
+	}
+	return nil
+}
+
+func validateSourceOPD(item *models.Item, sourceOPDID *uuid.UUID) error {
+	if item.CurrentLocation != models.LocationOPD || item.CurrentOPDID == nil {
+		return fmt.Errorf("%w: item is not held by an OPD", ErrInvalidItemLocation)
+	}
+	if sourceOPDID == nil {
+		return fmt.Errorf("%w: source_opd_id is required", ErrMissingOPD)
+	}
+	if *sourceOPDID != *item.CurrentOPDID {
+		return fmt.Errorf("%w: item is held by another OPD", ErrSourceOPDMismatch)
+	}
+	return nil
+}
+
+// requireActiveOPDs loads every referenced OPD and rejects unknown or
+// deactivated ones
+func requireActiveOPDs(opdRepo *repositories.OPDRepository, ids ...*uuid.UUID) error {
+	for _, id := range ids {
+		if id == nil {
+			continue
+		}
+		opd, err := opdRepo.GetOPD(id.String())
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrInactiveOPD, id)
+			}
+			return err
+		}
+		if !opd.IsActive {
+			return fmt.Errorf("%w: %s", ErrInactiveOPD, opd.Name)
+		}
+	}
+	return nil
+}
+
+// requireActiveWarehouses loads every referenced warehouse and rejects
+// unknown or deleted ones
+func requireActiveWarehouses(warehouseRepo *repositories.WarehouseRepository, ids ...*uuid.UUID) error {
+	for _, id := range ids {
+		if id == nil {
+			continue
+		}
+		warehouse, err := warehouseRepo.GetWarehouse(id.String())
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrInactiveWarehouse, id)
+			}
+			return err
+		}
+		if !warehouse.IsActive {
+			return fmt.Errorf("%w: %s", ErrInactiveWarehouse, warehouse.Name)
+		}
+	}
+	return nil
+}
+
+// recordMovement validates and records transaction t for a locked item,
+// moves the item and audits both writes. The actor is the user that
+// processed the transaction; a zero TransactionDate means now. A transaction
+// in transit leaves the item in transit instead of moving it.
+func recordMovement(tx *repositories.Repositories, item *models.Item, t *models.Transaction) error {
+	if err := fillWarehouses(tx, item, t); err != nil {
+		return err
+	}
+	if err := validateMovement(item, t); err != nil {
+		return err
+	}
+	if err := requireOutOfMaintenance(tx, item); err != nil {
+		return err
+	}
+	if err := requireActiveOPDs(tx.OPD, t.SourceOPDID, t.TargetOPDID); err != nil {
+		return err
+	}
+	if err := requireActiveWarehouses(tx.Warehouse, t.TargetWarehouseID); err != nil {
+		return err
+	}
+	if err := fillCustodians(tx, item, t); err != nil {
+		return err
+	}
+	if err := placeAtLocation(tx, t); err != nil {
+		return err
+	}
+
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.TransactionDate.IsZero() {
+		t.TransactionDate = time.Now()
+	}
+	if t.Status == "" {
+		t.Status = models.TransactionCompleted
+	}
+	t.ItemID = item.ID
+	t.SourceLocationID = item.LocationID
+	if err := validateLoan(t); err != nil {
+		return err
+	}
+	if err := tx.Transaction.CreateTransaction(t); err != nil {
+		return err
+	}
+
+	actor := models.Actor{ID: t.ProcessedByID, Name: t.ProcessedBy}
+	if err := recordAudit(tx, actor, models.AuditEntityTransaction, t.ID, models.AuditActionCreate, nil, t); err != nil {
+		return err
+	}
+
+	before := *item
+	if t.Status == models.TransactionInTransit {
+		// The item only arrives once the receiver confirms
+		item.CurrentLocation = models.LocationInTransit
+	} else {
+		applyMovement(item, t, t.TransactionDate)
+	}
+	if err := tx.Item.Update(item); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &before, item); err != nil {
+		return err
+	}
+
+	if t.Status == models.TransactionCompleted && t.Direction == models.DirectionOPDToWarehouse {
+		return settleLoan(tx, actor, t)
+	}
+	return nil
+}
+
+// fillWarehouses defaults the warehouse ends of transaction t: a movement
+// out of a warehouse leaves from the item's warehouse, and a return to the
+// warehouse goes to the default one
+func fillWarehouses(tx *repositories.Repositories, item *models.Item, t *models.Transaction) error {
+	if t.Direction.FromWarehouse() && t.SourceWarehouseID == nil && item.CurrentLocation == models.LocationWarehouse {
+		t.SourceWarehouseID = item.CurrentWarehouseID
+	}
+	if t.Direction == models.DirectionOPDToWarehouse && t.TargetWarehouseID == nil {
+		warehouseID, err := defaultWarehouseID(tx)
+		if err != nil {
+			return err
+		}
+		t.TargetWarehouseID = warehouseID
+	}
+	return nil
+}
+
+// fillCustodians records who is responsible for the item as the source
+// custodian of transaction t and checks the target custodian. Without one
+// named, a custodian working in the target OPD stays responsible.
+func fillCustodians(tx *repositories.Repositories, item *models.Item, t *models.Transaction) error {
+	t.SourceCustodianID = item.CurrentCustodianID
+	if t.TargetCustodianID == nil {
+		custodianID, err := custodianIn(tx, item.CurrentCustodianID, t.TargetOPDID)
+		if err != nil {
+			return err
+		}
+		t.TargetCustodianID = custodianID
+		return nil
+	}
+	if t.TargetOPDID == nil {
+		return fmt.Errorf("%w: target_custodian_id must be empty when the item goes to a warehouse", ErrInvalidCustodian)
+	}
+	_, err := requireCustodianOf(tx, *t.TargetCustodianID, *t.TargetOPDID)
+	return err
+}
+
+// awaitReceipt marks a transaction about to be recorded as in transit,
+// due for receipt ttl after its date
+func awaitReceipt(t *models.Transaction, ttl time.Duration) {
+	if t.TransactionDate.IsZero() {
+		t.TransactionDate = time.Now()
+	}
+	due := t.TransactionDate.Add(ttl)
+	t.Status = models.TransactionInTransit
+	t.ReceiptDueAt = &due
+}
+
+// returnToSource puts an item that was in transit back where the movement
+// started
+func returnToSource(item *models.Item, t *models.Transaction) {
+	item.CurrentOPDID = t.SourceOPDID
+	item.CurrentWarehouseID = t.SourceWarehouseID
+	item.CurrentCustodianID = t.SourceCustodianID
+	if t.SourceOPDID == nil {
+		item.CurrentLocation = models.LocationWarehouse
+	} else {
+		item.CurrentLocation = models.LocationOPD
+	}
+}
+
+// movementTo builds the movement that takes an item from where it is to
+// the warehouse or OPD siteID. ok is false when the item is already there.
+func movementTo(item *models.Item, location models.LocationType, siteID *uuid.UUID) (t *models.Transaction, ok bool) {
+	if item.CurrentLocation == location && sameID(item.SiteID(), siteID) {
+		return nil, false
+	}
+
+	t = &models.Transaction{}
+	switch {
+	case location == models.LocationWarehouse && item.CurrentLocation == models.LocationWarehouse:
+		t.Direction = models.DirectionWarehouseToWarehouse
+		t.SourceWarehouseID, t.TargetWarehouseID = item.CurrentWarehouseID, siteID
+	case location == models.LocationWarehouse:
+		t.Direction = models.DirectionOPDToWarehouse
+		t.SourceOPDID, t.TargetWarehouseID = item.CurrentOPDID, siteID
+	case item.CurrentLocation == models.LocationWarehouse:
+		t.Direction = models.DirectionWarehouseToOPD
+		t.SourceWarehouseID, t.TargetOPDID = item.CurrentWarehouseID, siteID
+	default:
+		t.Direction = models.DirectionOPDToOPD
+		t.SourceOPDID, t.TargetOPDID = item.CurrentOPDID, siteID
+	}
+	return t, true
+}
+
+// applyMovement moves the item to where transaction t points
+func applyMovement(item *models.Item, t *models.Transaction, at time.Time) {
+	item.SpecificLocation = t.SpecificLocation
+	item.LocationID = t.LocationID
+	item.CurrentWarehouseID = t.TargetWarehouseID
+	item.CurrentCustodianID = t.TargetCustodianID
+	targetOPDID := t.TargetOPDID
+	switch t.Direction {
+	case models.DirectionWarehouseToOPD:
+		item.CurrentLocation = models.LocationOPD
+		item.CurrentOPDID = targetOPDID
+		item.ExitDate = &at
+	case models.DirectionOPDToWarehouse:
+		item.CurrentLocation = models.LocationWarehouse
+		item.CurrentOPDID = nil
+		item.ExitDate = nil
+	case models.DirectionOPDToOPD:
+		item.CurrentLocation = models.LocationOPD
+		item.CurrentOPDID = targetOPDID
+	case models.DirectionWarehouseToWarehouse:
+		item.CurrentLocation = models.LocationWarehouse
+	}
+}
+
+// placeAtLocation checks that the storage location of transaction t
+// belongs to where the movement ends and copies its full name to the
+// transaction's specific location
+func placeAtLocation(tx *repositories.Repositories, t *models.Transaction) error {
+	if t.LocationID == nil {
+		return nil
+	}
+	location, err := tx.Storage.GetStorageLocation(t.LocationID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrInvalidStorageLocation, t.LocationID)
+		}
+		return err
+	}
+
+	site, siteID := models.LocationOPD, t.TargetOPDID
+	if t.Direction.ToWarehouse() {
+		site, siteID = models.LocationWarehouse, t.TargetWarehouseID
+	}
+	if !location.IsAt(site, siteID) {
+		return fmt.Errorf("%w: %s is not at the destination", ErrInvalidStorageLocation, location.FullName)
+	}
+	t.SpecificLocation = location.FullName
+	return nil
+}
+
+// reverseDirection returns the movement that takes an item back along the
+// path of the given transaction, with its source and target OPD. The
+// warehouse ends simply swap.
+func reverseDirection(t *models.Transaction) (models.TransactionDirection, *uuid.UUID, *uuid.UUID) {
+	switch t.Direction {
+	case models.DirectionWarehouseToOPD:
+		return models.DirectionOPDToWarehouse, t.TargetOPDID, nil
+	case models.DirectionOPDToWarehouse:
+		return models.DirectionWarehouseToOPD, nil, t.SourceOPDID
+	default:
+		return t.Direction, t.TargetOPDID, t.SourceOPDID
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"warehouse-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateMovement(t *testing.T) {
+	warehouse, otherWarehouse := uuid.New(), uuid.New()
+	opd, otherOPD := uuid.New(), uuid.New()
+
+	inWarehouse := &models.Item{CurrentLocation: models.LocationWarehouse, CurrentWarehouseID: &warehouse}
+	atOPD := &models.Item{CurrentLocation: models.LocationOPD, CurrentOPDID: &opd}
+	inTransit := &models.Item{CurrentLocation: models.LocationInTransit}
+	disposed := &models.Item{CurrentLocation: models.LocationDisposed}
+
+	tests := []struct {
+		name string
+		item *models.Item
+		t    models.Transaction
+		want error
+	}{
+		{
+			name: "issue to an OPD",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToOPD, SourceWarehouseID: &warehouse, TargetOPDID: &opd},
+		},
+		{
+			name: "issue without target OPD",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToOPD, SourceWarehouseID: &warehouse},
+			want: ErrMissingOPD,
+		},
+		{
+			name: "issue naming a source OPD",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToOPD, SourceWarehouseID: &warehouse, SourceOPDID: &opd, TargetOPDID: &otherOPD},
+			want: ErrInvalidDirection,
+		},
+		{
+			name: "issue naming a target warehouse",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToOPD, SourceWarehouseID: &warehouse, TargetOPDID: &opd, TargetWarehouseID: &otherWarehouse},
+			want: ErrInvalidDirection,
+		},
+		{
+			name: "issue without source warehouse",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToOPD, TargetOPDID: &opd},
+			want: ErrMissingWarehouse,
+		},
+		{
+			name: "issue from another warehouse",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToOPD, SourceWarehouseID: &otherWarehouse, TargetOPDID: &opd},
+			want: ErrSourceWarehouseMismatch,
+		},
+		{
+			name: "issue an item held by an OPD",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToOPD, SourceWarehouseID: &warehouse, TargetOPDID: &otherOPD},
+			want: ErrInvalidItemLocation,
+		},
+		{
+			name: "return to the warehouse",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToWarehouse, SourceOPDID: &opd, TargetWarehouseID: &warehouse},
+		},
+		{
+			name: "return without target warehouse",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToWarehouse, SourceOPDID: &opd},
+			want: ErrMissingWarehouse,
+		},
+		{
+			name: "return naming a target OPD",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToWarehouse, SourceOPDID: &opd, TargetOPDID: &otherOPD, TargetWarehouseID: &warehouse},
+			want: ErrInvalidDirection,
+		},
+		{
+			name: "return naming a source warehouse",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToWarehouse, SourceOPDID: &opd, SourceWarehouseID: &otherWarehouse, TargetWarehouseID: &warehouse},
+			want: ErrInvalidDirection,
+		},
+		{
+			name: "return from another OPD",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToWarehouse, SourceOPDID: &otherOPD, TargetWarehouseID: &warehouse},
+			want: ErrSourceOPDMismatch,
+		},
+		{
+			name: "return without source OPD",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToWarehouse, TargetWarehouseID: &warehouse},
+			want: ErrMissingOPD,
+		},
+		{
+			name: "return an item in the warehouse",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionOPDToWarehouse, SourceOPDID: &opd, TargetWarehouseID: &warehouse},
+			want: ErrInvalidItemLocation,
+		},
+		{
+			name: "transfer between OPDs",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToOPD, SourceOPDID: &opd, TargetOPDID: &otherOPD},
+		},
+		{
+			name: "transfer to the same OPD",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToOPD, SourceOPDID: &opd, TargetOPDID: &opd},
+			want: ErrInvalidDirection,
+		},
+		{
+			name: "transfer without target OPD",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToOPD, SourceOPDID: &opd},
+			want: ErrMissingOPD,
+		},
+		{
+			name: "transfer naming a target warehouse",
+			item: atOPD,
+			t:    models.Transaction{Direction: models.DirectionOPDToOPD, SourceOPDID: &opd, TargetOPDID: &otherOPD, TargetWarehouseID: &warehouse},
+			want: ErrInvalidDirection,
+		},
+		{
+			name: "move between warehouses",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToWarehouse, SourceWarehouseID: &warehouse, TargetWarehouseID: &otherWarehouse},
+		},
+		{
+			name: "move to the same warehouse",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToWarehouse, SourceWarehouseID: &warehouse, TargetWarehouseID: &warehouse},
+			want: ErrInvalidDirection,
+		},
+		{
+			name: "move between warehouses without target",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToWarehouse, SourceWarehouseID: &warehouse},
+			want: ErrMissingWarehouse,
+		},
+		{
+			name: "move between warehouses naming an OPD",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToWarehouse, SourceWarehouseID: &warehouse, TargetWarehouseID: &otherWarehouse, TargetOPDID: &opd},
+			want: ErrInvalidDirection,
+		},
+		{
+			name: "move an item in transit",
+			item: inTransit,
+			t:    models.Transaction{Direction: models.DirectionOPDToOPD, SourceOPDID: &opd, TargetOPDID: &otherOPD},
+			want: ErrInvalidItemLocation,
+		},
+		{
+			name: "move a disposed item",
+			item: disposed,
+			t:    models.Transaction{Direction: models.DirectionWarehouseToOPD, SourceWarehouseID: &warehouse, TargetOPDID: &opd},
+			want: ErrInvalidItemLocation,
+		},
+		{
+			name: "unknown direction",
+			item: inWarehouse,
+			t:    models.Transaction{Direction: "Gudang → Gudang → OPD"},
+			want: ErrInvalidDirection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := tt.t
+			err := validateMovement(tt.item, &transaction)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("validateMovement() = %v, want no error", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("validateMovement() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyMovement(t *testing.T) {
+	warehouse, otherWarehouse := uuid.New(), uuid.New()
+	opd, otherOPD := uuid.New(), uuid.New()
+	custodian := uuid.New()
+	location := uuid.New()
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	earlier := at.AddDate(0, -1, 0)
+
+	tests := []struct {
+		name          string
+		item          models.Item
+		t             models.Transaction
+		wantLocation  models.LocationType
+		wantOPD       *uuid.UUID
+		wantWarehouse *uuid.UUID
+		wantCustodian *uuid.UUID
+		wantExitDate  *time.Time
+	}{
+		{
+			name:          "issue to an OPD",
+			item:          models.Item{CurrentLocation: models.LocationWarehouse, CurrentWarehouseID: &warehouse},
+			t:             models.Transaction{Direction: models.DirectionWarehouseToOPD, SourceWarehouseID: &warehouse, TargetOPDID: &opd, TargetCustodianID: &custodian},
+			wantLocation:  models.LocationOPD,
+			wantOPD:       &opd,
+			wantCustodian: &custodian,
+			wantExitDate:  &at,
+		},
+		{
+			name:          "return to the warehouse",
+			item:          models.Item{CurrentLocation: models.LocationOPD, CurrentOPDID: &opd, CurrentCustodianID: &custodian, ExitDate: &earlier},
+			t:             models.Transaction{Direction: models.DirectionOPDToWarehouse, SourceOPDID: &opd, TargetWarehouseID: &warehouse},
+			wantLocation:  models.LocationWarehouse,
+			wantWarehouse: &warehouse,
+		},
+		{
+			name:         "transfer between OPDs",
+			item:         models.Item{CurrentLocation: models.LocationOPD, CurrentOPDID: &opd, CurrentCustodianID: &custodian, ExitDate: &earlier},
+			t:            models.Transaction{Direction: models.DirectionOPDToOPD, SourceOPDID: &opd, TargetOPDID: &otherOPD},
+			wantLocation: models.LocationOPD,
+			wantOPD:      &otherOPD,
+			wantExitDate: &earlier,
+		},
+		{
+			name:          "move between warehouses",
+			item:          models.Item{CurrentLocation: models.LocationWarehouse, CurrentWarehouseID: &warehouse},
+			t:             models.Transaction{Direction: models.DirectionWarehouseToWarehouse, SourceWarehouseID: &warehouse, TargetWarehouseID: &otherWarehouse},
+			wantLocation:  models.LocationWarehouse,
+			wantWarehouse: &otherWarehouse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := tt.item
+			transaction := tt.t
+			transaction.SpecificLocation = "Rak A"
+			transaction.LocationID = &location
+			applyMovement(&item, &transaction, at)
+
+			if item.CurrentLocation != tt.wantLocation {
+				t.Errorf("CurrentLocation = %q, want %q", item.CurrentLocation, tt.wantLocation)
+			}
+			if !sameID(item.CurrentOPDID, tt.wantOPD) {
+				t.Errorf("CurrentOPDID = %v, want %v", item.CurrentOPDID, tt.wantOPD)
+			}
+			if !sameID(item.CurrentWarehouseID, tt.wantWarehouse) {
+				t.Errorf("CurrentWarehouseID = %v, want %v", item.CurrentWarehouseID, tt.wantWarehouse)
+			}
+			if !sameID(item.CurrentCustodianID, tt.wantCustodian) {
+				t.Errorf("CurrentCustodianID = %v, want %v", item.CurrentCustodianID, tt.wantCustodian)
+			}
+			switch {
+			case tt.wantExitDate == nil && item.ExitDate != nil:
+				t.Errorf("ExitDate = %v, want none", *item.ExitDate)
+			case tt.wantExitDate != nil && (item.ExitDate == nil || !item.ExitDate.Equal(*tt.wantExitDate)):
+				t.Errorf("ExitDate = %v, want %v", item.ExitDate, *tt.wantExitDate)
+			}
+			if item.SpecificLocation != "Rak A" || !sameID(item.LocationID, &location) {
+				t.Errorf("storage location = %q %v, want the transaction's", item.SpecificLocation, item.LocationID)
+			}
+		})
+	}
+}
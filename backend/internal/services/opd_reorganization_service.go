@@ -0,0 +1,320 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OPDReorganizationService struct {
+	repos              *repositories.Repositories
+	reorganizationRepo *repositories.OPDReorganizationRepository
+	opdRepo            *repositories.OPDRepository
+}
+
+func NewOPDReorganizationService(repos *repositories.Repositories) *OPDReorganizationService {
+	return &OPDReorganizationService{
+		repos:              repos,
+		reorganizationRepo: repos.Reorganization,
+		opdRepo:            repos.OPD,
+	}
+}
+
+func (s *OPDReorganizationService) GetReorganizations(params *models.ReorganizationSearchParams) ([]models.OPDReorganization, int64, error) {
+	return s.reorganizationRepo.GetReorganizations(params)
+}
+
+func (s *OPDReorganizationService) GetReorganization(id string) (*models.OPDReorganization, error) {
+	return s.reorganizationRepo.GetReorganization(id)
+}
+
+// GetLineage lists the OPDs an OPD was formed from and those it went into
+func (s *OPDReorganizationService) GetLineage(opdID uuid.UUID) ([]models.OPDLineage, error) {
+	if _, err := s.opdRepo.GetOPD(opdID.String()); err != nil {
+		return nil, err
+	}
+	return s.reorganizationRepo.GetLineage(opdID)
+}
+
+// MergeOPDs merges the source OPDs into the target. Every item of a source
+// moves to the target with an OPD → OPD transfer batch, its sub-units,
+// custodians and custodian accounts move along, and the source goes to the
+// trash. Nothing is written unless every source can be merged.
+func (s *OPDReorganizationService) MergeOPDs(actor models.Actor, req *models.MergeOPDsRequest) (*models.OPDReorganization, error) {
+	if (req.TargetOPDID == nil) == (req.NewOPD == nil) {
+		return nil, fmt.Errorf("%w: give either target_opd_id or new_opd", ErrInvalidReorganization)
+	}
+
+	reorganization := &models.OPDReorganization{
+		BaseModel:     models.BaseModel{ID: uuid.New()},
+		Kind:          models.ReorganizationMerge,
+		DecreeNumber:  req.DecreeNumber,
+		Notes:         req.Notes,
+		EffectiveAt:   time.Now(),
+		ProcessedBy:   actor.Name,
+		ProcessedByID: actor.ID,
+	}
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		sourceIDs := sortedIDs(req.SourceOPDIDs)
+		sources := make([]*models.OPD, 0, len(sourceIDs))
+		merged := make(map[uuid.UUID]bool, len(sourceIDs))
+		for _, id := range sourceIDs {
+			source, err := requireActiveOPD(tx, id)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, source)
+			merged[id] = true
+		}
+		// A source nested in another one goes first, so the outer one has
+		// no active units left by the time it is deleted
+		sort.SliceStable(sources, func(i, j int) bool { return len(sources[i].Path) > len(sources[j].Path) })
+
+		target, err := reorganizationTarget(tx, actor, req.TargetOPDID, req.NewOPD)
+		if err != nil {
+			return err
+		}
+		for _, source := range sources {
+			if strings.HasPrefix(target.Path, source.Path) {
+				return fmt.Errorf("%w: %s cannot merge into itself or one of its units", ErrInvalidReorganization, source.Name)
+			}
+		}
+
+		held := make(map[uuid.UUID][]uuid.UUID, len(sources))
+		for _, source := range sources {
+			if err := requireNothingInTransit(tx, source); err != nil {
+				return err
+			}
+			itemIDs, err := heldItemIDs(tx, source.ID)
+			if err != nil {
+				return err
+			}
+			if err := requireNoneInMaintenance(tx, source, itemIDs); err != nil {
+				return err
+			}
+			held[source.ID] = itemIDs
+		}
+
+		for _, source := range sources {
+			// Custodians move first, so they stay responsible for their items
+			if err := tx.Custodian.ReassignOPD(source.ID, target.ID); err != nil {
+				return err
+			}
+			itemIDs := held[source.ID]
+			notes := fmt.Sprintf("Penggabungan OPD %s ke %s", source.Name, target.Name)
+			lineage, err := handOver(tx, actor, reorganization, source, target, itemIDs, notes)
+			if err != nil {
+				return err
+			}
+			reorganization.Lineage = append(reorganization.Lineage, *lineage)
+
+			children, err := tx.OPD.GetActiveChildren(source.ID)
+			if err != nil {
+				return err
+			}
+			for i := range children {
+				if merged[children[i].ID] {
+					continue
+				}
+				if err := moveOPD(tx, actor, &children[i], target); err != nil {
+					return err
+				}
+			}
+			if err := tx.Reorganization.ReassignUsers(source.ID, target.ID); err != nil {
+				return err
+			}
+			if err := deleteOPD(tx, actor, source); err != nil {
+				return err
+			}
+		}
+		return tx.Reorganization.CreateReorganization(reorganization)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reorganizationRepo.GetReorganization(reorganization.ID.String())
+}
+
+// SplitOPD hands the items of an OPD to the OPDs of the parts, each with an
+// OPD → OPD transfer batch. The source is deleted only when asked and
+// once nothing is left with it.
+func (s *OPDReorganizationService) SplitOPD(actor models.Actor, req *models.SplitOPDRequest) (*models.OPDReorganization, error) {
+	reorganization := &models.OPDReorganization{
+		BaseModel:     models.BaseModel{ID: uuid.New()},
+		Kind:          models.ReorganizationSplit,
+		DecreeNumber:  req.DecreeNumber,
+		Notes:         req.Notes,
+		EffectiveAt:   time.Now(),
+		ProcessedBy:   actor.Name,
+		ProcessedByID: actor.ID,
+	}
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		source, err := requireActiveOPD(tx, req.SourceOPDID)
+		if err != nil {
+			return err
+		}
+		if req.DeleteSource {
+			if err := requireNothingInTransit(tx, source); err != nil {
+				return err
+			}
+		}
+
+		var partItemIDs []uuid.UUID
+		for _, part := range req.Parts {
+			partItemIDs = append(partItemIDs, part.ItemIDs...)
+		}
+		if err := requireNoneInMaintenance(tx, source, partItemIDs); err != nil {
+			return err
+		}
+
+		assigned := make(map[uuid.UUID]bool)
+		targets := make(map[uuid.UUID]bool)
+		for _, part := range req.Parts {
+			if (part.OPDID == nil) == (part.NewOPD == nil) {
+				return fmt.Errorf("%w: every part needs either opd_id or new_opd", ErrInvalidReorganization)
+			}
+			target, err := reorganizationTarget(tx, actor, part.OPDID, part.NewOPD)
+			if err != nil {
+				return err
+			}
+			if target.ID == source.ID || targets[target.ID] {
+				return fmt.Errorf("%w: %s is named twice", ErrInvalidReorganization, target.Name)
+			}
+			targets[target.ID] = true
+			for _, itemID := range part.ItemIDs {
+				if assigned[itemID] {
+					return fmt.Errorf("%w: item %s is in more than one part", ErrInvalidReorganization, itemID)
+				}
+				assigned[itemID] = true
+			}
+
+			notes := fmt.Sprintf("Pemecahan OPD %s ke %s", source.Name, target.Name)
+			lineage, err := handOver(tx, actor, reorganization, source, target, sortedIDs(part.ItemIDs), notes)
+			if err != nil {
+				return err
+			}
+			reorganization.Lineage = append(reorganization.Lineage, *lineage)
+		}
+
+		if req.DeleteSource {
+			left, err := heldItemIDs(tx, source.ID)
+			if err != nil {
+				return err
+			}
+			if len(left) > 0 {
+				return fmt.Errorf("%w: %d items of %s are not in any part", ErrInvalidReorganization, len(left), source.Name)
+			}
+			if err := deleteOPD(tx, actor, source); err != nil {
+				return err
+			}
+		}
+		return tx.Reorganization.CreateReorganization(reorganization)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reorganizationRepo.GetReorganization(reorganization.ID.String())
+}
+
+// requireActiveOPD loads an OPD that has to be active
+func requireActiveOPD(tx *repositories.Repositories, id uuid.UUID) (*models.OPD, error) {
+	opd, err := tx.OPD.GetOPD(id.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrInactiveOPD, id)
+		}
+		return nil, err
+	}
+	if !opd.IsActive {
+		return nil, fmt.Errorf("%w: %s", ErrInactiveOPD, opd.Name)
+	}
+	return opd, nil
+}
+
+// reorganizationTarget loads the existing OPD opdID or creates newOPD
+func reorganizationTarget(tx *repositories.Repositories, actor models.Actor, opdID *uuid.UUID, newOPD *models.CreateOPDRequest) (*models.OPD, error) {
+	if opdID != nil {
+		return requireActiveOPD(tx, *opdID)
+	}
+	return createOPD(tx, actor, newOPD)
+}
+
+// requireNothingInTransit refuses to reorganize an OPD while deliveries to
+// or from it are awaiting receipt, since they would arrive at or bounce
+// back to an OPD that no longer exists
+func requireNothingInTransit(tx *repositories.Repositories, opd *models.OPD) error {
+	serialNumbers, err := tx.Reorganization.FindInTransit(opd.ID)
+	if err != nil {
+		return err
+	}
+	if len(serialNumbers) > 0 {
+		return fmt.Errorf("%w: %s has items in transit: %s", ErrReorganizationBlocked, opd.Name, strings.Join(serialNumbers, ", "))
+	}
+	return nil
+}
+
+// requireNoneInMaintenance refuses to reorganize an OPD while any of the
+// items to hand over has an open maintenance ticket, listing all of them
+func requireNoneInMaintenance(tx *repositories.Repositories, opd *models.OPD, itemIDs []uuid.UUID) error {
+	items, total, err := tx.Maintenance.FindBlockingItems(itemIDs, blockingItemsLimit)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return &BlockedError{Err: ErrReorganizationBlocked, State: "in maintenance", Name: opd.Name, Items: items, Total: total}
+	}
+	return nil
+}
+
+// heldItemIDs lists the active items an OPD holds
+func heldItemIDs(tx *repositories.Repositories, opdID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	params := &models.ItemSearchParams{Location: string(models.LocationOPD), OPDID: opdID.String()}
+	err := tx.Item.StreamAll(models.AccessScope{}, params, func(items []models.Item) error {
+		for i := range items {
+			ids = append(ids, items[i].ID)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// handOver moves itemIDs from source to target in one transfer batch and
+// returns the lineage link between the two
+func handOver(tx *repositories.Repositories, actor models.Actor, reorganization *models.OPDReorganization, source, target *models.OPD, itemIDs []uuid.UUID, notes string) (*models.OPDLineage, error) {
+	lineage := &models.OPDLineage{
+		BaseModel:        models.BaseModel{ID: uuid.New()},
+		ReorganizationID: reorganization.ID,
+		PredecessorID:    source.ID,
+		SuccessorID:      target.ID,
+		ItemCount:        len(itemIDs),
+	}
+	if len(itemIDs) == 0 {
+		return lineage, nil
+	}
+
+	batch, err := recordTransferBatch(tx, models.AccessScope{}, &models.CreateTransferBatchRequest{
+		ItemIDs:       itemIDs,
+		Direction:     models.DirectionOPDToOPD,
+		SourceOPDID:   &source.ID,
+		TargetOPDID:   &target.ID,
+		Notes:         notes,
+		ProcessedBy:   actor.Name,
+		ProcessedByID: actor.ID,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	lineage.BatchID = &batch.ID
+	reorganization.ItemCount += len(itemIDs)
+	return lineage, nil
+}
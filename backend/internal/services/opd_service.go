@@ -1,36 +1,55 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 	"warehouse-system/internal/models"
 	"warehouse-system/internal/repositories"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type OPDService struct {
+	repos   *repositories.Repositories
 	opdRepo *repositories.OPDRepository
 }
 
-func NewOPDService(opdRepo *repositories.OPDRepository) *OPDService {
-	return &OPDService{opdRepo: opdRepo}
+func NewOPDService(repos *repositories.Repositories) *OPDService {
+	return &OPDService{
+		repos:   repos,
+		opdRepo: repos.OPD,
+	}
 }
 
 func (s *OPDService) GetOPDs() ([]models.OPD, error) {
 	return s.opdRepo.GetOPDs()
 }
 
-func (s *OPDService) CreateOPD(req *models.CreateOPDRequest) (*models.OPD, error) {
-	opd := &models.OPD{
-		ID:          uuid.New().String(),
-		Name:        req.Name,
-		Description: req.Description,
-		IsActive:    true,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+// GetOPDTree returns the active OPDs nested under their parents, with the
+// items each holds rolled up to the units above it
+func (s *OPDService) GetOPDTree() ([]*models.OPDNode, error) {
+	opds, err := s.opdRepo.GetOPDs()
+	if err != nil {
+		return nil, err
 	}
+	counts, err := s.opdRepo.CountItems()
+	if err != nil {
+		return nil, err
+	}
+	return buildOPDTree(opds, counts), nil
+}
 
-	if err := s.opdRepo.CreateOPD(opd); err != nil {
+func (s *OPDService) CreateOPD(actor models.Actor, req *models.CreateOPDRequest) (*models.OPD, error) {
+	var opd *models.OPD
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		var err error
+		opd, err = createOPD(tx, actor, req)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -41,20 +60,317 @@ func (s *OPDService) GetOPD(id string) (*models.OPD, error) {
 	return s.opdRepo.GetOPD(id)
 }
 
-func (s *OPDService) UpdateOPD(id string, req *models.CreateOPDRequest) (*models.OPD, error) {
+func (s *OPDService) UpdateOPD(actor models.Actor, id string, req *models.CreateOPDRequest) (*models.OPD, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.OPD.GetOPD(id)
+		if err != nil {
+			return err
+		}
+		if before.IsActive {
+			if err := requireUniqueOPDName(tx, req.Name, before.ID); err != nil {
+				return err
+			}
+		}
+
+		opd := &models.OPD{
+			Name:        req.Name,
+			Description: req.Description,
+		}
+		if err := tx.OPD.UpdateOPD(id, opd); err != nil {
+			return err
+		}
+
+		after, err := tx.OPD.GetOPD(id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityOPD, after.ID, models.AuditActionUpdate, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.opdRepo.GetOPD(id)
+}
+
+// MoveOPD puts an OPD, with the units under it, below another parent or at
+// the top level
+func (s *OPDService) MoveOPD(actor models.Actor, id string, req *models.MoveOPDRequest) (*models.OPD, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.OPD.GetOPD(id)
+		if err != nil {
+			return err
+		}
+		if !before.IsActive {
+			return gorm.ErrRecordNotFound
+		}
+
+		var parent *models.OPD
+		if req.ParentID != nil {
+			if parent, err = requireOPDParent(tx, before, *req.ParentID); err != nil {
+				return err
+			}
+		}
+		return moveOPD(tx, actor, before, parent)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.opdRepo.GetOPD(id)
+}
+
+// DeleteOPD moves an OPD to the trash. The units under it have to go
+// first, and so do its items: with reassignTo they are moved to that OPD
+// with an OPD → OPD transfer batch, in the same database transaction.
+func (s *OPDService) DeleteOPD(actor models.Actor, id string, reassignTo *uuid.UUID) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.OPD.GetOPD(id)
+		if err != nil {
+			return err
+		}
+		if !before.IsActive {
+			return gorm.ErrRecordNotFound
+		}
+
+		if reassignTo != nil {
+			if *reassignTo == before.ID {
+				return fmt.Errorf("%w: reassign_to is the OPD being deleted", ErrInvalidReassignment)
+			}
+			target, err := requireActiveOPD(tx, *reassignTo)
+			if err != nil {
+				return err
+			}
+			itemIDs, err := heldItemIDs(tx, before.ID)
+			if err != nil {
+				return err
+			}
+			if len(itemIDs) > 0 {
+				_, err = recordTransferBatch(tx, models.AccessScope{}, &models.CreateTransferBatchRequest{
+					ItemIDs:       itemIDs,
+					Direction:     models.DirectionOPDToOPD,
+					SourceOPDID:   &before.ID,
+					TargetOPDID:   &target.ID,
+					Notes:         fmt.Sprintf("Pengalihan barang dari OPD %s yang dihapus", before.Name),
+					ProcessedBy:   actor.Name,
+					ProcessedByID: actor.ID,
+				}, 0)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return deleteOPD(tx, actor, before)
+	})
+}
+
+func (s *OPDService) GetDeletedOPDs() ([]models.OPD, error) {
+	return s.opdRepo.GetDeletedOPDs()
+}
+
+// RestoreOPD takes an OPD out of the trash, unless an active OPD has
+// taken its name in the meantime or its parent is still in the trash
+func (s *OPDService) RestoreOPD(actor models.Actor, id string) (*models.OPD, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.OPD.GetOPD(id)
+		if err != nil {
+			return err
+		}
+		if before.IsActive {
+			return ErrNotInTrash
+		}
+		if err := requireUniqueOPDName(tx, before.Name, before.ID); err != nil {
+			return err
+		}
+		if before.ParentID != nil {
+			if err := requireActiveOPDs(tx.OPD, before.ParentID); err != nil {
+				return fmt.Errorf("%w: restore the parent OPD first", err)
+			}
+		}
+
+		after := *before
+		after.Restore()
+		if err := tx.OPD.UpdateDeletion(&after); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityOPD, before.ID, models.AuditActionRestore, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.opdRepo.GetOPD(id)
+}
+
+// PurgeOPD permanently removes an OPD from the trash. An OPD that is
+// still referenced by any record cannot be purged.
+func (s *OPDService) PurgeOPD(actor models.Actor, id string) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.OPD.GetOPD(id)
+		if err != nil {
+			return err
+		}
+		if before.IsActive {
+			return ErrNotInTrash
+		}
+		referenced, err := tx.OPD.IsReferenced(before.ID)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return ErrStillReferenced
+		}
+
+		if err := tx.OPD.Purge(before.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityOPD, before.ID, models.AuditActionPurge, before, nil)
+	})
+}
+
+// requireUniqueOPDName refuses a name already used by another active OPD
+func requireUniqueOPDName(tx *repositories.Repositories, name string, excludeID uuid.UUID) error {
+	existing, err := tx.OPD.FindActiveByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID == excludeID {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDuplicateName, name)
+}
+
+// createOPD creates an active OPD, under req.ParentID when given
+func createOPD(tx *repositories.Repositories, actor models.Actor, req *models.CreateOPDRequest) (*models.OPD, error) {
 	opd := &models.OPD{
+		BaseModel:   models.BaseModel{ID: uuid.New()},
 		Name:        req.Name,
 		Description: req.Description,
-		UpdatedAt:   time.Now(),
+		SoftDelete:  models.Active(),
 	}
+	opd.Path = "/" + opd.ID.String() + "/"
 
-	if err := s.opdRepo.UpdateOPD(id, opd); err != nil {
+	if err := requireUniqueOPDName(tx, req.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if req.ParentID != nil {
+		parent, err := requireOPDParent(tx, opd, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		opd.ParentID = &parent.ID
+		opd.Path = parent.Path + opd.ID.String() + "/"
+	}
+	if err := tx.OPD.CreateOPD(opd); err != nil {
 		return nil, err
 	}
+	if err := recordAudit(tx, actor, models.AuditEntityOPD, opd.ID, models.AuditActionCreate, nil, opd); err != nil {
+		return nil, err
+	}
+	return opd, nil
+}
 
-	return s.opdRepo.GetOPD(id)
+// moveOPD puts opd and its units under parent, or at the top level when
+// parent is nil. The parent has been checked by requireOPDParent.
+func moveOPD(tx *repositories.Repositories, actor models.Actor, opd, parent *models.OPD) error {
+	after := *opd
+	after.ParentID = nil
+	after.Path = "/" + opd.ID.String() + "/"
+	if parent != nil {
+		after.ParentID = &parent.ID
+		after.Path = parent.Path + opd.ID.String() + "/"
+	}
+	if after.Path == opd.Path {
+		return nil
+	}
+
+	if err := tx.OPD.MoveSubtree(&after, opd.Path); err != nil {
+		return err
+	}
+	return recordAudit(tx, actor, models.AuditEntityOPD, opd.ID, models.AuditActionUpdate, opd, &after)
+}
+
+// deleteOPD moves an active OPD to the trash once no active units or items
+// are left with it. Items in transit to or from it count as well.
+func deleteOPD(tx *repositories.Repositories, actor models.Actor, opd *models.OPD) error {
+	hasChildren, err := tx.OPD.HasActiveChildren(opd.ID)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return fmt.Errorf("%w: %s", ErrOPDHasSubUnits, opd.Name)
+	}
+	items, total, err := tx.OPD.FindBlockingItems(opd.ID, blockingItemsLimit)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return &BlockedError{Name: opd.Name, Items: items, Total: total}
+	}
+
+	after := *opd
+	after.MarkDeleted(actor, time.Now())
+	if err := tx.OPD.UpdateDeletion(&after); err != nil {
+		return err
+	}
+	return recordAudit(tx, actor, models.AuditEntityOPD, opd.ID, models.AuditActionDelete, opd, &after)
 }
 
-func (s *OPDService) DeleteOPD(id string) error {
-	return s.opdRepo.DeleteOPD(id)
-}
\ No newline at end of file
+// requireOPDParent loads the OPD that opd is to be placed under. It must be
+// active and cannot be opd itself or one of its units.
+func requireOPDParent(tx *repositories.Repositories, opd *models.OPD, parentID uuid.UUID) (*models.OPD, error) {
+	parent, err := tx.OPD.GetOPD(parentID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrInactiveOPD, parentID)
+		}
+		return nil, err
+	}
+	if !parent.IsActive {
+		return nil, fmt.Errorf("%w: %s", ErrInactiveOPD, parent.Name)
+	}
+	if opd.Path != "" && strings.HasPrefix(parent.Path, opd.Path) {
+		return nil, fmt.Errorf("%w: %s is %s or one of its units", ErrInvalidOPDParent, parent.Name, opd.Name)
+	}
+	return parent, nil
+}
+
+// buildOPDTree nests OPDs under their parents and rolls the item counts up
+func buildOPDTree(opds []models.OPD, counts map[uuid.UUID]int64) []*models.OPDNode {
+	nodes := make(map[uuid.UUID]*models.OPDNode, len(opds))
+	for i := range opds {
+		nodes[opds[i].ID] = &models.OPDNode{
+			OPD:       opds[i],
+			ItemCount: counts[opds[i].ID],
+			Children:  []*models.OPDNode{},
+		}
+	}
+
+	roots := []*models.OPDNode{}
+	for i := range opds {
+		node := nodes[opds[i].ID]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	for _, root := range roots {
+		sumOPDItems(root)
+	}
+	return roots
+}
+
+func sumOPDItems(node *models.OPDNode) int64 {
+	total := node.ItemCount
+	for _, child := range node.Children {
+		total += sumOPDItems(child)
+	}
+	node.TotalItems = total
+	return total
+}
@@ -1,19 +1,58 @@
 package services
 
-import "warehouse-system/internal/repositories"
+import (
+	"warehouse-system/internal/config"
+	"warehouse-system/internal/repositories"
+)
 
 type Services struct {
-	Item        ItemService
-	Transaction TransactionService
-	OPD         OPDService
-	Category    CategoryService
+	Item           *ItemService
+	Transaction    *TransactionService
+	OPD            *OPDService
+	Category       *CategoryService
+	Dashboard      *DashboardService
+	Auth           *AuthService
+	User           *UserService
+	Audit          *AuditService
+	Import         *ImportService
+	Export         *ExportService
+	Label          *LabelService
+	Handover       *HandoverService
+	StockOpname    *StockOpnameService
+	Transfer       *TransferBatchService
+	ItemRequest    *ItemRequestService
+	Loan           *LoanService
+	Maintenance    *MaintenanceService
+	Disposal       *DisposalService
+	Storage        *StorageLocationService
+	Warehouse      *WarehouseService
+	Reorganization *OPDReorganizationService
+	Custodian      *CustodianService
 }
 
-func NewServices(repos *repositories.Repositories) *Services {
+func NewServices(repos *repositories.Repositories, cfg *config.Config) *Services {
 	return &Services{
-		Item:        NewItemService(repos.Item, repos.Transaction),
-		Transaction: NewTransactionService(repos.Transaction, repos.Item),
-		OPD:         NewOPDService(repos.OPD),
-		Category:    NewCategoryService(repos.Category),
+		Item:           NewItemService(repos),
+		Transaction:    NewTransactionService(repos, cfg.ReceiptTTL),
+		OPD:            NewOPDService(repos),
+		Category:       NewCategoryService(repos),
+		Dashboard:      NewDashboardService(repos.Item, repos.Transaction, repos.OPD, repos.Category),
+		Auth:           NewAuthService(repos.User, cfg),
+		User:           NewUserService(repos.User, repos.OPD),
+		Audit:          NewAuditService(repos.Audit),
+		Import:         NewImportService(repos),
+		Export:         NewExportService(repos.Item, repos.Transaction),
+		Label:          NewLabelService(repos.Item, cfg.LabelURLTemplate),
+		Handover:       NewHandoverService(repos, cfg.HandoverTemplatePath),
+		StockOpname:    NewStockOpnameService(repos),
+		Transfer:       NewTransferBatchService(repos, cfg.ReceiptTTL),
+		ItemRequest:    NewItemRequestService(repos, cfg.ReceiptTTL),
+		Loan:           NewLoanService(repos.Transaction),
+		Maintenance:    NewMaintenanceService(repos),
+		Disposal:       NewDisposalService(repos, cfg.DisposalApprovals),
+		Storage:        NewStorageLocationService(repos),
+		Warehouse:      NewWarehouseService(repos),
+		Reorganization: NewOPDReorganizationService(repos),
+		Custodian:      NewCustodianService(repos, cfg.HandoverTemplatePath),
 	}
-}
\ No newline at end of file
+}
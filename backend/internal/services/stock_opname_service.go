@@ -0,0 +1,415 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StockOpnameService struct {
+	repos      *repositories.Repositories
+	opnameRepo *repositories.StockOpnameRepository
+}
+
+func NewStockOpnameService(repos *repositories.Repositories) *StockOpnameService {
+	return &StockOpnameService{
+		repos:      repos,
+		opnameRepo: repos.StockOpname,
+	}
+}
+
+func (s *StockOpnameService) GetStockOpnames(scope models.AccessScope, params *models.StockOpnameSearchParams) ([]models.StockOpname, int64, error) {
+	return s.opnameRepo.GetStockOpnames(scope, params)
+}
+
+func (s *StockOpnameService) GetStockOpname(scope models.AccessScope, id string) (*models.StockOpname, error) {
+	opname, err := s.opnameRepo.GetStockOpname(id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsStockOpname(opname) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return opname, nil
+}
+
+// OpenStockOpname starts a count of the Gudang or an OPD and snapshots the
+// items currently recorded there. A location has at most one open count.
+func (s *StockOpnameService) OpenStockOpname(actor models.Actor, req *models.OpenStockOpnameRequest) (*models.StockOpname, error) {
+	switch req.Location {
+	case models.LocationWarehouse:
+		if req.OPDID != nil {
+			return nil, fmt.Errorf("%w: opd_id must be empty when counting the warehouse", ErrInvalidOpnameLocation)
+		}
+	case models.LocationOPD:
+		if req.OPDID == nil {
+			return nil, fmt.Errorf("%w: opd_id is required", ErrMissingOPD)
+		}
+		if req.WarehouseID != nil {
+			return nil, fmt.Errorf("%w: warehouse_id must be empty when counting an OPD", ErrInvalidOpnameLocation)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOpnameLocation, req.Location)
+	}
+
+	var opname *models.StockOpname
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		warehouseID := req.WarehouseID
+		if req.Location == models.LocationWarehouse && warehouseID == nil {
+			defaultID, err := defaultWarehouseID(tx)
+			if err != nil {
+				return err
+			}
+			warehouseID = defaultID
+		}
+		if err := requireActiveOPDs(tx.OPD, req.OPDID); err != nil {
+			return err
+		}
+		if err := requireActiveWarehouses(tx.Warehouse, warehouseID); err != nil {
+			return err
+		}
+
+		opname = &models.StockOpname{
+			BaseModel:   models.BaseModel{ID: uuid.New()},
+			Location:    req.Location,
+			OPDID:       req.OPDID,
+			WarehouseID: warehouseID,
+			Status:      models.StockOpnameOpen,
+			Notes:       req.Notes,
+			OpenedBy:    actor.Name,
+			OpenedByID:  actor.ID,
+		}
+
+		if err := tx.StockOpname.LockSite(opname.Location, *opname.SiteID()); err != nil {
+			return err
+		}
+		_, err := tx.StockOpname.FindOpenStockOpname(opname.Location, *opname.SiteID())
+		if err == nil {
+			return ErrOpnameAlreadyOpen
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		params := &models.ItemSearchParams{Location: string(req.Location)}
+		if req.OPDID != nil {
+			params.OPDID = req.OPDID.String()
+		}
+		if warehouseID != nil {
+			params.WarehouseID = warehouseID.String()
+		}
+		err = tx.Item.StreamAll(models.AccessScope{}, params, func(items []models.Item) error {
+			for i := range items {
+				item := &items[i]
+				opname.Entries = append(opname.Entries, models.StockOpnameEntry{
+					BaseModel:         models.BaseModel{ID: uuid.New()},
+					OpnameID:          opname.ID,
+					SerialNumber:      item.SerialNumber,
+					ItemID:            &item.ID,
+					Expected:          true,
+					ExpectedCondition: item.Condition,
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.StockOpname.CreateStockOpname(opname)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.opnameRepo.GetStockOpname(opname.ID.String())
+}
+
+// ScanItem records a serial number as seen during an open count. Scanning
+// the same serial again updates the observed condition.
+func (s *StockOpnameService) ScanItem(scope models.AccessScope, actor models.Actor, id string, req *models.ScanStockOpnameRequest) (*models.StockOpnameEntry, error) {
+	if req.Condition != "" && !req.Condition.IsValid() {
+		return nil, ErrInvalidCondition
+	}
+	serialNumber := strings.TrimSpace(req.SerialNumber)
+
+	var entry *models.StockOpnameEntry
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		opname, err := tx.StockOpname.GetStockOpnameForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsStockOpname(opname) {
+			return gorm.ErrRecordNotFound
+		}
+		if opname.Status != models.StockOpnameOpen {
+			return ErrOpnameClosed
+		}
+
+		entry, err = tx.StockOpname.FindEntry(opname.ID, serialNumber)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			entry, err = unexpectedEntry(tx, opname.ID, serialNumber)
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		entry.Scanned = true
+		entry.ScannedAt = &now
+		entry.ScannedBy = actor.Name
+		entry.ScannedCondition = req.Condition
+		if entry.ScannedCondition == "" {
+			entry.ScannedCondition = entry.ExpectedCondition
+		}
+		if req.Notes != "" {
+			entry.Notes = req.Notes
+		}
+		return tx.StockOpname.SaveEntry(entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// unexpectedEntry starts an entry for a serial number that was not in the
+// snapshot. It is linked to the item when the serial is registered.
+func unexpectedEntry(tx *repositories.Repositories, opnameID uuid.UUID, serialNumber string) (*models.StockOpnameEntry, error) {
+	entry := &models.StockOpnameEntry{
+		BaseModel:    models.BaseModel{ID: uuid.New()},
+		OpnameID:     opnameID,
+		SerialNumber: serialNumber,
+	}
+	item, err := tx.Item.GetBySerialNumber(serialNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entry, nil
+		}
+		return nil, err
+	}
+	entry.ItemID = &item.ID
+	entry.ExpectedCondition = item.Condition
+	return entry, nil
+}
+
+// CloseStockOpname ends a count, stores the result of every entry and
+// returns the variance report
+func (s *StockOpnameService) CloseStockOpname(scope models.AccessScope, actor models.Actor, id string) (*models.StockOpnameReport, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		locked, err := tx.StockOpname.GetStockOpnameForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsStockOpname(locked) {
+			return gorm.ErrRecordNotFound
+		}
+		if locked.Status != models.StockOpnameOpen {
+			return ErrOpnameClosed
+		}
+
+		opname, err := tx.StockOpname.GetStockOpname(id)
+		if err != nil {
+			return err
+		}
+		for i := range opname.Entries {
+			opname.Entries[i].Result = opname.Entries[i].EvaluateResult()
+		}
+		if err := tx.StockOpname.SetEntryResults(opname.Entries); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		locked.Status = models.StockOpnameClosed
+		locked.ClosedAt = &now
+		locked.ClosedBy = actor.Name
+		locked.ClosedByID = actor.ID
+		return tx.StockOpname.UpdateStockOpname(locked)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReport(scope, id)
+}
+
+// GetReport groups the entries of a count by result. Open counts are
+// evaluated as if they were closed now.
+func (s *StockOpnameService) GetReport(scope models.AccessScope, id string) (*models.StockOpnameReport, error) {
+	opname, err := s.GetStockOpname(scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.StockOpnameReport{
+		Found:            []models.StockOpnameEntry{},
+		Missing:          []models.StockOpnameEntry{},
+		Unexpected:       []models.StockOpnameEntry{},
+		ConditionChanged: []models.StockOpnameEntry{},
+	}
+	for _, entry := range opname.Entries {
+		if entry.Expected {
+			report.ExpectedCount++
+		}
+		if entry.Scanned {
+			report.ScannedCount++
+		}
+		result := entry.Result
+		if opname.Status == models.StockOpnameOpen {
+			result = entry.EvaluateResult()
+			entry.Result = result
+		}
+		switch result {
+		case models.OpnameResultFound:
+			report.Found = append(report.Found, entry)
+		case models.OpnameResultMissing:
+			report.Missing = append(report.Missing, entry)
+		case models.OpnameResultUnexpected:
+			report.Unexpected = append(report.Unexpected, entry)
+		case models.OpnameResultConditionChanged:
+			report.ConditionChanged = append(report.ConditionChanged, entry)
+		}
+	}
+	opname.Entries = nil
+	report.Opname = opname
+	return report, nil
+}
+
+// PostCorrections writes the chosen variances of a closed count back to the
+// items: observed conditions, transactions moving unexpected items to the
+// counted location, and missing items marked as lost. It can run once.
+func (s *StockOpnameService) PostCorrections(scope models.AccessScope, actor models.Actor, id string, req *models.PostStockOpnameCorrectionsRequest) (*models.StockOpnameCorrections, error) {
+	corrections := &models.StockOpnameCorrections{
+		TransactionIDs: []uuid.UUID{},
+		Skipped:        []string{},
+	}
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		locked, err := tx.StockOpname.GetStockOpnameForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsStockOpname(locked) {
+			return gorm.ErrRecordNotFound
+		}
+		if locked.Status != models.StockOpnameClosed {
+			return ErrOpnameNotClosed
+		}
+		if locked.CorrectedAt != nil {
+			return ErrOpnameCorrected
+		}
+
+		opname, err := tx.StockOpname.GetStockOpname(id)
+		if err != nil {
+			return err
+		}
+		// Items are locked in ID order, like every other bulk write
+		entries := make(map[uuid.UUID]*models.StockOpnameEntry, len(opname.Entries))
+		itemIDs := make([]uuid.UUID, 0, len(opname.Entries))
+		for i := range opname.Entries {
+			entry := &opname.Entries[i]
+			if entry.Result == models.OpnameResultFound {
+				continue
+			}
+			if entry.ItemID == nil {
+				corrections.Skipped = append(corrections.Skipped, entry.SerialNumber)
+				continue
+			}
+			entries[*entry.ItemID] = entry
+			itemIDs = append(itemIDs, *entry.ItemID)
+		}
+
+		for _, itemID := range sortedIDs(itemIDs) {
+			entry := entries[itemID]
+
+			// Items trashed or disposed of since the count are left alone
+			item, err := tx.Item.GetForUpdate(itemID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				corrections.Skipped = append(corrections.Skipped, entry.SerialNumber)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if item.CurrentLocation == models.LocationDisposed {
+				corrections.Skipped = append(corrections.Skipped, entry.SerialNumber)
+				continue
+			}
+
+			if entry.Result == models.OpnameResultMissing {
+				// An item that left the site since the snapshot is not lost
+				if item.CurrentLocation != opname.Location || !sameID(item.SiteID(), opname.SiteID()) {
+					corrections.Skipped = append(corrections.Skipped, entry.SerialNumber)
+					continue
+				}
+				if req.MarkMissingLost && item.Condition != models.ConditionBroken {
+					if err := updateCondition(tx, actor, item, models.ConditionBroken); err != nil {
+						return err
+					}
+					corrections.ItemsMarkedLost++
+				}
+				continue
+			}
+
+			if req.ApplyConditions && entry.ScannedCondition != "" && entry.ScannedCondition != item.Condition {
+				if err := updateCondition(tx, actor, item, entry.ScannedCondition); err != nil {
+					return err
+				}
+				corrections.ConditionsUpdated++
+			}
+
+			if entry.Result == models.OpnameResultUnexpected && req.RelocateUnexpected {
+				// Deliveries in transit are settled by their receipt
+				if item.CurrentLocation == models.LocationInTransit {
+					corrections.Skipped = append(corrections.Skipped, entry.SerialNumber)
+					continue
+				}
+				// So are items held by an open maintenance ticket
+				if err := requireOutOfMaintenance(tx, item); err != nil {
+					if !errors.Is(err, ErrItemInMaintenance) {
+						return err
+					}
+					corrections.Skipped = append(corrections.Skipped, entry.SerialNumber)
+					continue
+				}
+				transaction, ok := movementTo(item, opname.Location, opname.SiteID())
+				if !ok {
+					continue
+				}
+				transaction.SpecificLocation = req.SpecificLocation
+				transaction.Notes = fmt.Sprintf("Koreksi stock opname %s", opname.ID)
+				transaction.ProcessedBy = actor.Name
+				transaction.ProcessedByID = actor.ID
+				if err := recordMovement(tx, item, transaction); err != nil {
+					return fmt.Errorf("relocating %s: %w", entry.SerialNumber, err)
+				}
+				corrections.ItemsRelocated++
+				corrections.TransactionIDs = append(corrections.TransactionIDs, transaction.ID)
+			}
+		}
+
+		now := time.Now()
+		locked.CorrectedAt = &now
+		locked.CorrectedBy = actor.Name
+		return tx.StockOpname.UpdateStockOpname(locked)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return corrections, nil
+}
+
+// updateCondition changes the condition of a locked item and audits it
+func updateCondition(tx *repositories.Repositories, actor models.Actor, item *models.Item, condition models.Condition) error {
+	before := *item
+	item.Condition = condition
+	if err := tx.Item.Update(item); err != nil {
+		return err
+	}
+	return recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &before, item)
+}
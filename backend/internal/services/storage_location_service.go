@@ -0,0 +1,371 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// storagePathSeparator joins the names of a storage location's ancestors
+// into its full name
+const storagePathSeparator = " / "
+
+type StorageLocationService struct {
+	repos       *repositories.Repositories
+	storageRepo *repositories.StorageLocationRepository
+	itemRepo    repositories.ItemRepository
+}
+
+func NewStorageLocationService(repos *repositories.Repositories) *StorageLocationService {
+	return &StorageLocationService{
+		repos:       repos,
+		storageRepo: repos.Storage,
+		itemRepo:    repos.Item,
+	}
+}
+
+// GetStorageLocationTree returns the location tree of a site with item
+// counts. Restricted users always get the tree of their own OPD; others get
+// the default warehouse unless they pick a site.
+func (s *StorageLocationService) GetStorageLocationTree(scope models.AccessScope, params *models.StorageLocationSearchParams) ([]*models.StorageLocationNode, error) {
+	site := models.LocationWarehouse
+	var siteID uuid.UUID
+	switch {
+	case scope.Restricted:
+		site, siteID = models.LocationOPD, *scope.OPDID
+	case params.OPDID != "" && params.WarehouseID != "":
+		return nil, fmt.Errorf("%w: pick either opd_id or warehouse_id", ErrInvalidStorageLocation)
+	case params.OPDID != "":
+		parsed, err := uuid.Parse(params.OPDID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: opd_id", ErrInvalidStorageLocation)
+		}
+		site, siteID = models.LocationOPD, parsed
+	case params.WarehouseID != "":
+		parsed, err := uuid.Parse(params.WarehouseID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: warehouse_id", ErrInvalidStorageLocation)
+		}
+		siteID = parsed
+	default:
+		warehouseID, err := defaultWarehouseID(s.repos)
+		if err != nil {
+			return nil, err
+		}
+		siteID = *warehouseID
+	}
+
+	locations, err := s.storageRepo.GetStorageLocations(site, siteID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.storageRepo.CountItems(site, siteID)
+	if err != nil {
+		return nil, err
+	}
+	return buildLocationTree(locations, counts), nil
+}
+
+func (s *StorageLocationService) GetStorageLocation(scope models.AccessScope, id string) (*models.StorageLocation, error) {
+	location, err := s.storageRepo.GetStorageLocation(id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsStorageLocation(location) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return location, nil
+}
+
+// CreateStorageLocation adds a node to a site's tree. Kinds only nest
+// downwards, so a rack can hold bins but not rooms. A root node without a
+// site goes to the default warehouse.
+func (s *StorageLocationService) CreateStorageLocation(scope models.AccessScope, req *models.CreateStorageLocationRequest) (*models.StorageLocation, error) {
+	if !req.Kind.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStorageKind, req.Kind)
+	}
+
+	location := &models.StorageLocation{
+		BaseModel:   models.BaseModel{ID: uuid.New()},
+		Kind:        req.Kind,
+		Name:        req.Name,
+		Code:        req.Code,
+		Description: req.Description,
+	}
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		if req.ParentID == nil {
+			if req.OPDID != nil && req.WarehouseID != nil {
+				return fmt.Errorf("%w: pick either opd_id or warehouse_id", ErrInvalidStorageLocation)
+			}
+			location.OPDID, location.WarehouseID = req.OPDID, req.WarehouseID
+			if scope.Restricted {
+				location.OPDID, location.WarehouseID = scope.OPDID, nil
+			}
+			if location.OPDID == nil && location.WarehouseID == nil {
+				warehouseID, err := defaultWarehouseID(tx)
+				if err != nil {
+					return err
+				}
+				location.WarehouseID = warehouseID
+			}
+			location.Path = "/" + location.ID.String() + "/"
+			location.FullName = req.Name
+			if err := requireActiveOPDs(tx.OPD, location.OPDID); err != nil {
+				return err
+			}
+			if err := requireActiveWarehouses(tx.Warehouse, location.WarehouseID); err != nil {
+				return err
+			}
+		} else {
+			parent, err := tx.Storage.GetStorageLocation(req.ParentID.String())
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: parent %s", ErrInvalidStorageLocation, req.ParentID)
+				}
+				return err
+			}
+			if req.Kind.Level() <= parent.Kind.Level() {
+				return fmt.Errorf("%w: a %s cannot hold a %s", ErrInvalidStorageKind, parent.Kind, req.Kind)
+			}
+			location.ParentID = &parent.ID
+			location.OPDID = parent.OPDID
+			location.WarehouseID = parent.WarehouseID
+			location.Path = parent.Path + location.ID.String() + "/"
+			location.FullName = parent.FullName + storagePathSeparator + req.Name
+		}
+		if !scope.AllowsStorageLocation(location) {
+			return ErrForbidden
+		}
+		if err := requireUniqueSibling(tx, location); err != nil {
+			return err
+		}
+		return tx.Storage.CreateStorageLocation(location)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storageRepo.GetStorageLocation(location.ID.String())
+}
+
+// UpdateStorageLocation renames a node. The full names below it and the
+// specific location of items placed there follow.
+func (s *StorageLocationService) UpdateStorageLocation(scope models.AccessScope, id string, req *models.UpdateStorageLocationRequest) (*models.StorageLocation, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		location, err := tx.Storage.GetStorageLocation(id)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsStorageLocation(location) {
+			return gorm.ErrRecordNotFound
+		}
+
+		oldFullName := location.FullName
+		location.Code = req.Code
+		location.Description = req.Description
+		if req.Name == location.Name {
+			return tx.Storage.UpdateStorageLocation(location)
+		}
+
+		location.Name = req.Name
+		if err := requireUniqueSibling(tx, location); err != nil {
+			return err
+		}
+		location.FullName = req.Name
+		if location.ParentID != nil {
+			parent, err := tx.Storage.GetStorageLocation(location.ParentID.String())
+			if err != nil {
+				return err
+			}
+			location.FullName = parent.FullName + storagePathSeparator + req.Name
+		}
+		if err := tx.Storage.UpdateStorageLocation(location); err != nil {
+			return err
+		}
+		return tx.Storage.RenameSubtree(location, oldFullName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storageRepo.GetStorageLocation(id)
+}
+
+// DeleteStorageLocation removes a node that nothing refers to: no
+// sub-locations, no items placed there and no movements into or out of it
+func (s *StorageLocationService) DeleteStorageLocation(scope models.AccessScope, id string) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		location, err := tx.Storage.GetStorageLocation(id)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsStorageLocation(location) {
+			return gorm.ErrRecordNotFound
+		}
+
+		referenced, err := tx.Storage.IsReferenced(location.ID)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return fmt.Errorf("%w: %s", ErrStorageLocationInUse, location.FullName)
+		}
+		return tx.Storage.DeleteStorageLocation(location.ID)
+	})
+}
+
+// MoveItems moves items to another storage location of the site holding
+// them. The items stay at their site, so no transaction is recorded; each
+// move is kept as an ItemMove instead.
+func (s *StorageLocationService) MoveItems(scope models.AccessScope, actor models.Actor, req *models.MoveItemsRequest) ([]models.ItemMove, error) {
+	var moves []models.ItemMove
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		location, err := tx.Storage.GetStorageLocation(req.LocationID.String())
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrInvalidStorageLocation, req.LocationID)
+			}
+			return err
+		}
+
+		now := time.Now()
+		for _, itemID := range sortedIDs(req.ItemIDs) {
+			item, err := tx.Item.GetForUpdate(itemID)
+			if err != nil {
+				return fmt.Errorf("item %s: %w", itemID, err)
+			}
+			if !scope.AllowsItem(item) {
+				return fmt.Errorf("item %s: %w", item.SerialNumber, ErrForbidden)
+			}
+			if !location.IsAt(item.CurrentLocation, item.SiteID()) {
+				return fmt.Errorf("%w: %s is not where item %s is", ErrInvalidStorageLocation, location.FullName, item.SerialNumber)
+			}
+			if item.LocationID != nil && *item.LocationID == location.ID {
+				continue
+			}
+
+			move := models.ItemMove{
+				BaseModel:            models.BaseModel{ID: uuid.New()},
+				ItemID:               item.ID,
+				OPDID:                item.CurrentOPDID,
+				WarehouseID:          item.CurrentWarehouseID,
+				FromLocationID:       item.LocationID,
+				FromSpecificLocation: item.SpecificLocation,
+				ToLocationID:         location.ID,
+				Notes:                req.Notes,
+				MovedAt:              now,
+				MovedBy:              actor.Name,
+				MovedByID:            actor.ID,
+			}
+			if err := tx.Storage.CreateItemMove(&move); err != nil {
+				return err
+			}
+
+			before := *item
+			item.LocationID = &location.ID
+			item.SpecificLocation = location.FullName
+			if err := tx.Item.Update(item); err != nil {
+				return err
+			}
+			if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &before, item); err != nil {
+				return err
+			}
+			moves = append(moves, move)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return moves, nil
+}
+
+// GetItemMoves lists the moves of an item within its sites
+func (s *StorageLocationService) GetItemMoves(scope models.AccessScope, itemID uuid.UUID) ([]models.ItemMove, error) {
+	item, err := s.itemRepo.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsItem(item) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.storageRepo.GetItemMoves(itemID)
+}
+
+// placeNewItem puts a newly registered item at a storage location of the
+// warehouse it enters
+func placeNewItem(tx *repositories.Repositories, item *models.Item, locationID uuid.UUID) error {
+	location, err := tx.Storage.GetStorageLocation(locationID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrInvalidStorageLocation, locationID)
+		}
+		return err
+	}
+	if !location.IsAt(item.CurrentLocation, item.SiteID()) {
+		return fmt.Errorf("%w: %s is not in the item's warehouse", ErrInvalidStorageLocation, location.FullName)
+	}
+	item.LocationID = &location.ID
+	item.SpecificLocation = location.FullName
+	return nil
+}
+
+// requireUniqueSibling refuses a node name already used by another child of
+// the same parent
+func requireUniqueSibling(tx *repositories.Repositories, location *models.StorageLocation) error {
+	site, siteID := location.Site()
+	sibling, err := tx.Storage.FindSibling(site, siteID, location.ParentID, location.Name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if sibling.ID == location.ID {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDuplicateName, location.Name)
+}
+
+// buildLocationTree nests the nodes of a site under their parents and rolls
+// the item counts up
+func buildLocationTree(locations []models.StorageLocation, counts map[uuid.UUID]int64) []*models.StorageLocationNode {
+	nodes := make(map[uuid.UUID]*models.StorageLocationNode, len(locations))
+	for i := range locations {
+		nodes[locations[i].ID] = &models.StorageLocationNode{
+			StorageLocation: locations[i],
+			ItemCount:       counts[locations[i].ID],
+			Children:        []*models.StorageLocationNode{},
+		}
+	}
+
+	roots := []*models.StorageLocationNode{}
+	for i := range locations {
+		node := nodes[locations[i].ID]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	for _, root := range roots {
+		sumItems(root)
+	}
+	return roots
+}
+
+func sumItems(node *models.StorageLocationNode) int64 {
+	total := node.ItemCount
+	for _, child := range node.Children {
+		total += sumItems(child)
+	}
+	node.TotalItems = total
+	return total
+}
@@ -1,109 +1,413 @@
 package services
 
 import (
+	"errors"
 	"time"
 	"warehouse-system/internal/models"
 	"warehouse-system/internal/repositories"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type TransactionService struct {
+	repos           *repositories.Repositories
 	transactionRepo *repositories.TransactionRepository
-	itemRepo        *repositories.ItemRepository
+	receiptTTL      time.Duration
 }
 
-func NewTransactionService(transactionRepo *repositories.TransactionRepository, itemRepo *repositories.ItemRepository) *TransactionService {
+func NewTransactionService(repos *repositories.Repositories, receiptTTL time.Duration) *TransactionService {
 	return &TransactionService{
-		transactionRepo: transactionRepo,
-		itemRepo:        itemRepo,
+		repos:           repos,
+		transactionRepo: repos.Transaction,
+		receiptTTL:      receiptTTL,
 	}
 }
 
-func (s *TransactionService) GetTransactions(page, limit int, direction string) ([]models.Transaction, int64, error) {
-	return s.transactionRepo.GetTransactions(page, limit, direction)
+func (s *TransactionService) GetTransactions(scope models.AccessScope, params *models.TransactionSearchParams) ([]models.Transaction, int64, error) {
+	from, to, err := parseDateRange(params.From, params.To)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.transactionRepo.GetTransactions(scope, params, from, to)
 }
 
-func (s *TransactionService) CreateTransaction(req *models.CreateTransactionRequest) (*models.Transaction, error) {
-	// Get the item to validate it exists and get current location
-	item, err := s.itemRepo.GetItem(req.ItemID)
+func (s *TransactionService) CreateTransaction(scope models.AccessScope, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+	// Issuing items out of the warehouse is reserved for warehouse staff
+	if scope.Restricted && req.Direction.FromWarehouse() {
+		return nil, ErrWarehouseStaffOnly
+	}
+
+	loanDueDate, err := parseLoanDueDate(req.LoanDueDate)
 	if err != nil {
 		return nil, err
 	}
 
-	transaction := &models.Transaction{
-		ID:               uuid.New().String(),
-		ItemID:           req.ItemID,
-		Direction:        req.Direction,
-		SourceOPDID:      req.SourceOPDID,
-		TargetOPDID:      req.TargetOPDID,
-		SpecificLocation: req.SpecificLocation,
-		Notes:            req.Notes,
-		TransactionDate:  time.Now(),
-		ProcessedBy:      req.ProcessedBy,
+	var transaction *models.Transaction
+	err = s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		// Lock the item so concurrent movements of it are serialized
+		item, err := tx.Item.GetForUpdate(req.ItemID)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsItem(item) {
+			return ErrForbidden
+		}
+
+		transaction = &models.Transaction{
+			ItemID:            req.ItemID,
+			Direction:         req.Direction,
+			SourceOPDID:       req.SourceOPDID,
+			TargetOPDID:       req.TargetOPDID,
+			SourceWarehouseID: req.SourceWarehouseID,
+			TargetWarehouseID: req.TargetWarehouseID,
+			TargetCustodianID: req.TargetCustodianID,
+			SpecificLocation:  req.SpecificLocation,
+			LocationID:        req.LocationID,
+			Notes:             req.Notes,
+			ProcessedBy:       req.ProcessedBy,
+			ProcessedByID:     req.ProcessedByID,
+			LoanDueDate:       loanDueDate,
+		}
+		if req.RequireReceipt {
+			awaitReceipt(transaction, s.receiptTTL)
+		}
+		return recordMovement(tx, item, transaction)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Create transaction
-	if err := s.transactionRepo.CreateTransaction(transaction); err != nil {
+	// Return transaction with relations
+	return s.transactionRepo.GetTransaction(transaction.ID.String())
+}
+
+func (s *TransactionService) GetTransaction(scope models.AccessScope, id string) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.GetTransaction(id)
+	if err != nil {
 		return nil, err
 	}
+	if !scope.AllowsTransaction(transaction) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return transaction, nil
+}
 
-	// Update item location based on transaction
-	updateReq := &models.CreateItemRequest{
-		SerialNumber:     item.SerialNumber,
-		CategoryID:       item.CategoryID,
-		Brand:            item.Brand,
-		Model:            item.Model,
-		Condition:        item.Condition,
-		Description:      item.Description,
-		SpecificLocation: req.SpecificLocation,
-	}
-
-	// Update location based on direction
-	switch req.Direction {
-	case "Gudang → OPD":
-		updateReq.CurrentLocation = "OPD"
-		updateReq.CurrentOPDID = req.TargetOPDID
-	case "OPD → Gudang":
-		updateReq.CurrentLocation = "Gudang"
-		updateReq.CurrentOPDID = nil
-	case "OPD → OPD":
-		updateReq.CurrentLocation = "OPD"
-		updateReq.CurrentOPDID = req.TargetOPDID
-	}
-
-	// Update item
-	_, err = s.itemRepo.UpdateItem(req.ItemID, updateReq)
+// UpdateTransaction edits the notes of a transaction and keeps a record of
+// the change. Location fields can only be corrected by ReverseTransaction.
+func (s *TransactionService) UpdateTransaction(id string, req *models.UpdateTransactionRequest) (*models.Transaction, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		transaction, err := tx.Transaction.GetTransaction(id)
+		if err != nil {
+			return err
+		}
+		if transaction.Notes == req.Notes {
+			return nil
+		}
+
+		edit := &models.TransactionEdit{
+			BaseModel:     models.BaseModel{ID: uuid.New()},
+			TransactionID: transaction.ID,
+			Field:         "notes",
+			OldValue:      transaction.Notes,
+			NewValue:      req.Notes,
+			EditedBy:      req.EditedBy,
+			EditedByID:    req.EditedByID,
+		}
+		if err := tx.Transaction.UpdateNotes(transaction, edit); err != nil {
+			return err
+		}
+
+		actor := models.Actor{ID: req.EditedByID, Name: req.EditedBy}
+		changes := map[string]string{"notes": transaction.Notes}
+		return recordAudit(tx, actor, models.AuditEntityTransaction, transaction.ID, models.AuditActionUpdate, changes, map[string]string{"notes": req.Notes})
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Return transaction with relations
-	return s.transactionRepo.GetTransaction(transaction.ID)
+	return s.transactionRepo.GetTransaction(id)
 }
 
-func (s *TransactionService) GetTransaction(id string) (*models.Transaction, error) {
-	return s.transactionRepo.GetTransaction(id)
+// ReverseTransaction undoes a movement by recording a compensating
+// transaction and moving the item back to where it came from. Only the
+// latest movement of an item can be reversed, so history stays consistent.
+func (s *TransactionService) ReverseTransaction(scope models.AccessScope, id string, req *models.ReverseTransactionRequest) (*models.Transaction, error) {
+	var reversal *models.Transaction
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		original, err := tx.Transaction.GetTransaction(id)
+		if err != nil {
+			return err
+		}
+		if !scope.AllowsTransaction(original) {
+			return gorm.ErrRecordNotFound
+		}
+		if original.ReversalOfID != nil {
+			return ErrReversalNotReversible
+		}
+		if original.Status == models.TransactionInTransit {
+			return ErrTransactionInTransit
+		}
+		if original.Bounced() {
+			return ErrTransactionBounced
+		}
+		if original.Reversal != nil {
+			return ErrAlreadyReversed
+		}
+
+		item, err := tx.Item.GetForUpdate(original.ItemID)
+		if err != nil {
+			return err
+		}
+
+		latest, err := tx.Transaction.GetLatestForItem(item.ID)
+		if err != nil {
+			return err
+		}
+		if latest.ID != original.ID {
+			return ErrNotLatestTransaction
+		}
+
+		direction, sourceOPDID, targetOPDID := reverseDirection(original)
+		now := time.Now()
+		reversal = &models.Transaction{
+			BaseModel:         models.BaseModel{ID: uuid.New()},
+			ItemID:            item.ID,
+			Direction:         direction,
+			SourceOPDID:       sourceOPDID,
+			TargetOPDID:       targetOPDID,
+			SourceWarehouseID: original.TargetWarehouseID,
+			TargetWarehouseID: original.SourceWarehouseID,
+			SpecificLocation:  req.SpecificLocation,
+			LocationID:        req.LocationID,
+			SourceLocationID:  item.LocationID,
+			Notes:             req.Reason,
+			TransactionDate:   now,
+			ProcessedBy:       req.ProcessedBy,
+			ProcessedByID:     req.ProcessedByID,
+			ReversalOfID:      &original.ID,
+		}
+		if err := validateMovement(item, reversal); err != nil {
+			return err
+		}
+		if err := requireOutOfMaintenance(tx, item); err != nil {
+			return err
+		}
+		if err := requireActiveOPDs(tx.OPD, reversal.TargetOPDID); err != nil {
+			return err
+		}
+		if err := requireActiveWarehouses(tx.Warehouse, reversal.TargetWarehouseID); err != nil {
+			return err
+		}
+		// The custodian responsible before goes back to being so, if still
+		// working in that OPD
+		reversal.SourceCustodianID = item.CurrentCustodianID
+		reversal.TargetCustodianID, err = custodianIn(tx, original.SourceCustodianID, reversal.TargetOPDID)
+		if err != nil {
+			return err
+		}
+		// Without directions the item goes back to the storage location it left
+		if req.LocationID == nil && req.SpecificLocation == "" {
+			reversal.LocationID = original.SourceLocationID
+		}
+		if err := placeAtLocation(tx, reversal); err != nil {
+			return err
+		}
+		if err := tx.Transaction.CreateTransaction(reversal); err != nil {
+			return err
+		}
+
+		actor := models.Actor{ID: req.ProcessedByID, Name: req.ProcessedBy}
+		if err := recordAudit(tx, actor, models.AuditEntityTransaction, original.ID, models.AuditActionReverse, nil, reversal); err != nil {
+			return err
+		}
+
+		before := *item
+		applyMovement(item, reversal, now)
+		if err := tx.Item.Update(item); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &before, item); err != nil {
+			return err
+		}
+
+		// A reversed delivery is owed to its request again
+		if err := returnToRequest(tx, actor, original, item, "reversed"); err != nil {
+			return err
+		}
+
+		// Reversing a loan returns it; reversing a return puts the loan back out
+		if original.Direction == models.DirectionOPDToWarehouse {
+			return reopenLoan(tx, actor, original)
+		}
+		if direction == models.DirectionOPDToWarehouse {
+			return settleLoan(tx, actor, reversal)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transactionRepo.GetTransaction(reversal.ID.String())
 }
 
-func (s *TransactionService) UpdateTransaction(id string, req *models.CreateTransactionRequest) (*models.Transaction, error) {
-	transaction := &models.Transaction{
-		ItemID:           req.ItemID,
-		Direction:        req.Direction,
-		SourceOPDID:      req.SourceOPDID,
-		TargetOPDID:      req.TargetOPDID,
-		SpecificLocation: req.SpecificLocation,
-		Notes:            req.Notes,
-		ProcessedBy:      req.ProcessedBy,
+// ConfirmReceipt completes an in-transit movement and moves the item to its
+// destination. A condition differing from the recorded one updates the item.
+func (s *TransactionService) ConfirmReceipt(scope models.AccessScope, actor models.Actor, id string, req *models.ConfirmReceiptRequest) (*models.Transaction, error) {
+	if req.Condition != "" && !req.Condition.IsValid() {
+		return nil, ErrInvalidCondition
 	}
 
-	if err := s.transactionRepo.UpdateTransaction(id, transaction); err != nil {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		transaction, item, err := lockInTransit(tx, scope, id)
+		if err != nil {
+			return err
+		}
+
+		before := *transaction
+		now := time.Now()
+		transaction.Status = models.TransactionCompleted
+		transaction.ReceiptAt = &now
+		transaction.ReceiptBy = actor.Name
+		transaction.ReceiptByID = actor.ID
+		transaction.ReceiptNotes = req.Notes
+		if req.Condition != "" && req.Condition != item.Condition {
+			transaction.ReceiptCondition = req.Condition
+		}
+		if err := tx.Transaction.UpdateReceipt(transaction); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, models.AuditEntityTransaction, transaction.ID, models.AuditActionUpdate, &before, transaction); err != nil {
+			return err
+		}
+
+		itemBefore := *item
+		applyMovement(item, transaction, now)
+		if transaction.ReceiptCondition != "" {
+			item.Condition = transaction.ReceiptCondition
+		}
+		if err := tx.Item.Update(item); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &itemBefore, item); err != nil {
+			return err
+		}
+
+		if transaction.Direction == models.DirectionOPDToWarehouse {
+			return settleLoan(tx, actor, transaction)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return s.transactionRepo.GetTransaction(id)
 }
 
-func (s *TransactionService) DeleteTransaction(id string) error {
-	return s.transactionRepo.DeleteTransaction(id)
-}
\ No newline at end of file
+// RejectReceipt turns an in-transit movement back and returns the item to
+// where it was sent from
+func (s *TransactionService) RejectReceipt(scope models.AccessScope, actor models.Actor, id string, req *models.RejectReceiptRequest) (*models.Transaction, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		transaction, item, err := lockInTransit(tx, scope, id)
+		if err != nil {
+			return err
+		}
+		return bounceReceipt(tx, actor, transaction, item, models.TransactionRejected, req.Reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transactionRepo.GetTransaction(id)
+}
+
+// ExpireReceipts bounces every in-transit movement whose receipt deadline
+// has passed and returns how many were expired
+func (s *TransactionService) ExpireReceipts(now time.Time) (int, error) {
+	ids, err := s.transactionRepo.GetOverdueReceipts(now)
+	if err != nil {
+		return 0, err
+	}
+
+	actor := models.Actor{Name: "system"}
+	expired := 0
+	for _, id := range ids {
+		err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+			transaction, item, err := lockInTransit(tx, models.AccessScope{}, id.String())
+			if err != nil {
+				return err
+			}
+			return bounceReceipt(tx, actor, transaction, item, models.TransactionExpired, "Receipt deadline passed")
+		})
+		// Confirmed or rejected since the query ran
+		if errors.Is(err, ErrNotInTransit) {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// lockInTransit locks the item of an in-transit movement and checks that
+// the caller is on the receiving side. The transaction is read after the
+// lock so a concurrent confirmation is seen. Items moved to the trash while
+// in transit are locked too, so their movement can still be settled.
+func lockInTransit(tx *repositories.Repositories, scope models.AccessScope, id string) (*models.Transaction, *models.Item, error) {
+	transaction, err := tx.Transaction.GetTransaction(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !scope.AllowsTransaction(transaction) {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	if scope.Restricted && !sameID(transaction.TargetOPDID, scope.OPDID) {
+		return nil, nil, ErrReceiverOnly
+	}
+
+	item, err := tx.Item.GetForUpdate(transaction.ItemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		item, err = tx.Item.GetDeletedForUpdate(transaction.ItemID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	transaction, err = tx.Transaction.GetTransaction(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if transaction.Status != models.TransactionInTransit {
+		return nil, nil, ErrNotInTransit
+	}
+	return transaction, item, nil
+}
+
+func bounceReceipt(tx *repositories.Repositories, actor models.Actor, transaction *models.Transaction, item *models.Item, status models.TransactionStatus, reason string) error {
+	before := *transaction
+	now := time.Now()
+	transaction.Status = status
+	transaction.ReceiptAt = &now
+	transaction.ReceiptBy = actor.Name
+	transaction.ReceiptByID = actor.ID
+	transaction.ReceiptNotes = reason
+	if err := tx.Transaction.UpdateReceipt(transaction); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, actor, models.AuditEntityTransaction, transaction.ID, models.AuditActionUpdate, &before, transaction); err != nil {
+		return err
+	}
+
+	itemBefore := *item
+	returnToSource(item, transaction)
+	if err := tx.Item.Update(item); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, actor, models.AuditEntityItem, item.ID, models.AuditActionUpdate, &itemBefore, item); err != nil {
+		return err
+	}
+	return returnToRequest(tx, actor, transaction, item, string(status))
+}
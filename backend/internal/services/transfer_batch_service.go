@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TransferBatchService struct {
+	repos        *repositories.Repositories
+	transferRepo *repositories.TransferBatchRepository
+	receiptTTL   time.Duration
+}
+
+func NewTransferBatchService(repos *repositories.Repositories, receiptTTL time.Duration) *TransferBatchService {
+	return &TransferBatchService{
+		repos:        repos,
+		transferRepo: repos.Transfer,
+		receiptTTL:   receiptTTL,
+	}
+}
+
+func (s *TransferBatchService) GetTransferBatches(scope models.AccessScope, params *models.TransferBatchSearchParams) ([]models.TransferBatch, int64, error) {
+	from, to, err := parseDateRange(params.From, params.To)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.transferRepo.GetTransferBatches(scope, params, from, to)
+}
+
+func (s *TransferBatchService) GetTransferBatch(scope models.AccessScope, id string) (*models.TransferBatch, error) {
+	batch, err := s.transferRepo.GetTransferBatch(id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsTransferBatch(batch) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return batch, nil
+}
+
+// CreateTransferBatch moves every item of a delivery in one database
+// transaction. Each item gets its own Transaction linked to the batch; if
+// any item cannot move, nothing is recorded.
+func (s *TransferBatchService) CreateTransferBatch(scope models.AccessScope, req *models.CreateTransferBatchRequest) (*models.TransferBatch, error) {
+	var batch *models.TransferBatch
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		var err error
+		batch, err = recordTransferBatch(tx, scope, req, s.receiptTTL)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transferRepo.GetTransferBatch(batch.ID.String())
+}
+
+// recordTransferBatch writes a batch and the movement of each of its items
+// inside the caller's database transaction
+func recordTransferBatch(tx *repositories.Repositories, scope models.AccessScope, req *models.CreateTransferBatchRequest, receiptTTL time.Duration) (*models.TransferBatch, error) {
+	// Issuing items out of the warehouse is reserved for warehouse staff
+	if scope.Restricted && req.Direction.FromWarehouse() {
+		return nil, ErrWarehouseStaffOnly
+	}
+
+	loanDueDate, err := parseLoanDueDate(req.LoanDueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	itemIDs := sortedIDs(req.ItemIDs)
+	batch := &models.TransferBatch{
+		BaseModel:         models.BaseModel{ID: uuid.New()},
+		Direction:         req.Direction,
+		SourceOPDID:       req.SourceOPDID,
+		TargetOPDID:       req.TargetOPDID,
+		SourceWarehouseID: req.SourceWarehouseID,
+		TargetWarehouseID: req.TargetWarehouseID,
+		TargetCustodianID: req.TargetCustodianID,
+		SpecificLocation:  req.SpecificLocation,
+		LocationID:        req.LocationID,
+		Notes:             req.Notes,
+		BatchDate:         time.Now(),
+		ItemCount:         len(itemIDs),
+		RequestID:         req.RequestID,
+		ProcessedBy:       req.ProcessedBy,
+		ProcessedByID:     req.ProcessedByID,
+	}
+	if err := fillBatchWarehouses(tx, batch, itemIDs); err != nil {
+		return nil, err
+	}
+	if err := tx.Transfer.CreateTransferBatch(batch); err != nil {
+		return nil, err
+	}
+
+	for _, itemID := range itemIDs {
+		item, err := tx.Item.GetForUpdate(itemID)
+		if err != nil {
+			return nil, fmt.Errorf("item %s: %w", itemID, err)
+		}
+		if !scope.AllowsItem(item) {
+			return nil, fmt.Errorf("item %s: %w", item.SerialNumber, ErrForbidden)
+		}
+
+		transaction := &models.Transaction{
+			Direction:         req.Direction,
+			SourceOPDID:       req.SourceOPDID,
+			TargetOPDID:       req.TargetOPDID,
+			SourceWarehouseID: batch.SourceWarehouseID,
+			TargetWarehouseID: batch.TargetWarehouseID,
+			TargetCustodianID: req.TargetCustodianID,
+			SpecificLocation:  req.SpecificLocation,
+			LocationID:        req.LocationID,
+			Notes:             req.Notes,
+			ProcessedBy:       req.ProcessedBy,
+			ProcessedByID:     req.ProcessedByID,
+			TransactionDate:   batch.BatchDate,
+			BatchID:           &batch.ID,
+			RequestID:         req.RequestID,
+			LoanDueDate:       loanDueDate,
+		}
+		if req.RequireReceipt {
+			awaitReceipt(transaction, receiptTTL)
+		}
+		if err := recordMovement(tx, item, transaction); err != nil {
+			return nil, fmt.Errorf("item %s: %w", item.SerialNumber, err)
+		}
+	}
+	return batch, nil
+}
+
+// fillBatchWarehouses defaults the warehouse ends of a batch the way
+// fillWarehouses does for one transaction. Without a source warehouse the
+// batch leaves from the warehouse of its first item, and every other item
+// must be there too.
+func fillBatchWarehouses(tx *repositories.Repositories, batch *models.TransferBatch, itemIDs []uuid.UUID) error {
+	if batch.Direction.FromWarehouse() && batch.SourceWarehouseID == nil && len(itemIDs) > 0 {
+		first, err := tx.Item.GetByID(itemIDs[0])
+		if err != nil {
+			return fmt.Errorf("item %s: %w", itemIDs[0], err)
+		}
+		batch.SourceWarehouseID = first.CurrentWarehouseID
+	}
+	if batch.Direction == models.DirectionOPDToWarehouse && batch.TargetWarehouseID == nil {
+		warehouseID, err := defaultWarehouseID(tx)
+		if err != nil {
+			return err
+		}
+		batch.TargetWarehouseID = warehouseID
+	}
+	return nil
+}
+
+// sortedIDs removes duplicates and sorts IDs, so that rows are always
+// locked in the same order and concurrent batches cannot deadlock
+func sortedIDs(ids []uuid.UUID) []uuid.UUID {
+	sorted := uniqueIDs(ids)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+	return sorted
+}
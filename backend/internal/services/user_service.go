@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type UserService struct {
+	userRepo *repositories.UserRepository
+	opdRepo  *repositories.OPDRepository
+}
+
+func NewUserService(userRepo *repositories.UserRepository, opdRepo *repositories.OPDRepository) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+		opdRepo:  opdRepo,
+	}
+}
+
+// EnsureAdminUser creates the initial warehouse admin on an empty users
+// table so that a fresh installation can be signed in to
+func (s *UserService) EnsureAdminUser(username, password string) error {
+	count, err := s.userRepo.CountUsers()
+	if err != nil || count > 0 {
+		return err
+	}
+	if password == "" {
+		return errors.New("ADMIN_PASSWORD must be set to create the initial admin user")
+	}
+
+	_, err = s.CreateUser(&models.CreateUserRequest{
+		Username: username,
+		Password: password,
+		FullName: "Administrator",
+		Role:     models.RoleWarehouseAdmin,
+	})
+	return err
+}
+
+func (s *UserService) GetUsers() ([]models.User, error) {
+	return s.userRepo.GetUsers()
+}
+
+func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.User, error) {
+	if !req.Role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	// Only custodians are tied to an OPD
+	opdID := req.OPDID
+	if req.Role == models.RoleOPDCustodian {
+		if opdID == nil {
+			return nil, ErrOPDRequired
+		}
+		opd, err := s.opdRepo.GetOPD(opdID.String())
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrOPDRequired
+			}
+			return nil, err
+		}
+		if !opd.IsActive {
+			return nil, ErrOPDRequired
+		}
+	} else {
+		opdID = nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		BaseModel:    models.BaseModel{ID: uuid.New()},
+		Username:     req.Username,
+		PasswordHash: string(hash),
+		FullName:     req.FullName,
+		Role:         req.Role,
+		OPDID:        opdID,
+		IsActive:     true,
+	}
+
+	if err := s.userRepo.CreateUser(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
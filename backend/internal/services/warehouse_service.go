@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warehouse-system/internal/models"
+	"warehouse-system/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WarehouseService struct {
+	repos         *repositories.Repositories
+	warehouseRepo *repositories.WarehouseRepository
+}
+
+func NewWarehouseService(repos *repositories.Repositories) *WarehouseService {
+	return &WarehouseService{
+		repos:         repos,
+		warehouseRepo: repos.Warehouse,
+	}
+}
+
+func (s *WarehouseService) GetWarehouses() ([]models.Warehouse, error) {
+	return s.warehouseRepo.GetWarehouses()
+}
+
+func (s *WarehouseService) GetWarehouse(id string) (*models.Warehouse, error) {
+	return s.warehouseRepo.GetWarehouse(id)
+}
+
+func (s *WarehouseService) CreateWarehouse(actor models.Actor, req *models.CreateWarehouseRequest) (*models.Warehouse, error) {
+	warehouse := &models.Warehouse{
+		BaseModel:  models.BaseModel{ID: uuid.New()},
+		Name:       req.Name,
+		Address:    req.Address,
+		IsDefault:  req.IsDefault,
+		SoftDelete: models.Active(),
+	}
+
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		if err := requireUniqueWarehouseName(tx, req.Name, uuid.Nil); err != nil {
+			return err
+		}
+		if err := tx.Warehouse.CreateWarehouse(warehouse); err != nil {
+			return err
+		}
+		if warehouse.IsDefault {
+			if err := tx.Warehouse.ClearDefault(warehouse.ID); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, actor, models.AuditEntityWarehouse, warehouse.ID, models.AuditActionCreate, nil, warehouse)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+// UpdateWarehouse renames a warehouse or makes it the default. The default
+// moves rather than being unset, so is_default false on the default
+// warehouse is ignored.
+func (s *WarehouseService) UpdateWarehouse(actor models.Actor, id string, req *models.CreateWarehouseRequest) (*models.Warehouse, error) {
+	err := s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.Warehouse.GetWarehouse(id)
+		if err != nil {
+			return err
+		}
+		if !before.IsActive {
+			return gorm.ErrRecordNotFound
+		}
+		if err := requireUniqueWarehouseName(tx, req.Name, before.ID); err != nil {
+			return err
+		}
+
+		after := *before
+		after.Name = req.Name
+		after.Address = req.Address
+		after.IsDefault = before.IsDefault || req.IsDefault
+		if err := tx.Warehouse.UpdateWarehouse(&after); err != nil {
+			return err
+		}
+		if after.IsDefault && !before.IsDefault {
+			if err := tx.Warehouse.ClearDefault(after.ID); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, actor, models.AuditEntityWarehouse, after.ID, models.AuditActionUpdate, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.warehouseRepo.GetWarehouse(id)
+}
+
+// DeleteWarehouse deletes an empty warehouse other than the default
+func (s *WarehouseService) DeleteWarehouse(actor models.Actor, id string) error {
+	return s.repos.WithinTransaction(func(tx *repositories.Repositories) error {
+		before, err := tx.Warehouse.GetWarehouse(id)
+		if err != nil {
+			return err
+		}
+		if !before.IsActive {
+			return gorm.ErrRecordNotFound
+		}
+		if before.IsDefault {
+			return ErrDefaultWarehouse
+		}
+		holding, err := tx.Warehouse.IsHoldingItems(before.ID)
+		if err != nil {
+			return err
+		}
+		if holding {
+			return fmt.Errorf("%w: %s", ErrWarehouseNotEmpty, before.Name)
+		}
+
+		after := *before
+		after.MarkDeleted(actor, time.Now())
+		if err := tx.Warehouse.UpdateWarehouse(&after); err != nil {
+			return err
+		}
+		return recordAudit(tx, actor, models.AuditEntityWarehouse, before.ID, models.AuditActionDelete, before, &after)
+	})
+}
+
+// requireUniqueWarehouseName refuses a name already used by another active
+// warehouse
+func requireUniqueWarehouseName(tx *repositories.Repositories, name string, excludeID uuid.UUID) error {
+	existing, err := tx.Warehouse.FindActiveByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID == excludeID {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDuplicateName, name)
+}
+
+// defaultWarehouseID returns the ID of the default warehouse, where items
+// go when no warehouse is named
+func defaultWarehouseID(tx *repositories.Repositories) (*uuid.UUID, error) {
+	warehouse, err := tx.Warehouse.GetDefault()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: no default warehouse", ErrInactiveWarehouse)
+		}
+		return nil, err
+	}
+	return &warehouse.ID, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -10,6 +11,7 @@ import (
 
 	"warehouse-system/internal/config"
 	"warehouse-system/internal/handlers"
+	"warehouse-system/internal/models"
 	"warehouse-system/internal/repositories"
 	"warehouse-system/internal/services"
 	"warehouse-system/pkg/database"
@@ -23,6 +25,9 @@ func main() {
 
 	// Initialize configuration
 	cfg := config.Load()
+	if err := cfg.RequireJWTSecret(); err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
 
 	// Initialize database
 	db, err := database.Connect(cfg.DatabaseURL)
@@ -39,7 +44,20 @@ func main() {
 	repos := repositories.NewRepositories(db)
 
 	// Initialize services
-	svc := services.NewServices(repos)
+	svc := services.NewServices(repos, cfg)
+
+	// Make sure there is an account to sign in with
+	if err := svc.User.EnsureAdminUser(cfg.AdminUsername, cfg.AdminPassword); err != nil {
+		log.Fatal("Failed to create admin user:", err)
+	}
+
+	// Fail early on a broken BAST template
+	if err := svc.Handover.ValidateTemplate(); err != nil {
+		log.Fatal("Invalid handover document template:", err)
+	}
+
+	// Send overdue in-transit items back to where they came from
+	go expireReceipts(svc.Transaction)
 
 	// Initialize handlers
 	h := handlers.NewHandlers(svc)
@@ -67,36 +85,159 @@ func main() {
 	// API routes
 	api := r.Group("/api/v1")
 	{
+		// Authentication
+		api.POST("/auth/login", h.Login)
+		api.POST("/auth/refresh", h.RefreshToken)
+	}
+
+	// Role guards
+	adminOnly := h.RequireRoles(models.RoleWarehouseAdmin)
+	warehouseStaff := h.RequireRoles(models.RoleWarehouseAdmin, models.RoleWarehouseOperator)
+	canTransact := h.RequireRoles(models.RoleWarehouseAdmin, models.RoleWarehouseOperator, models.RoleOPDCustodian)
+	canAudit := h.RequireRoles(models.RoleWarehouseAdmin, models.RoleAuditor)
+	canReview := h.RequireRoles(models.RoleWarehouseAdmin, models.RoleWarehouseOperator, models.RoleAuditor)
+
+	protected := api.Group("")
+	protected.Use(h.AuthRequired())
+	{
+		// Current user
+		protected.GET("/auth/me", h.GetCurrentUser)
+		protected.POST("/auth/logout", h.Logout)
+
+		// Users
+		protected.GET("/users", adminOnly, h.GetUsers)
+		protected.POST("/users", adminOnly, h.CreateUser)
+
 		// Items
-		api.GET("/items", h.GetItems)
-		api.POST("/items", h.CreateItem)
-		api.GET("/items/:id", h.GetItem)
-		api.PUT("/items/:id", h.UpdateItem)
-		api.DELETE("/items/:id", h.DeleteItem)
-		api.GET("/items/search", h.SearchItems)
+		protected.GET("/items", h.GetItems)
+		protected.POST("/items", warehouseStaff, h.CreateItem)
+		protected.GET("/items/:id", h.GetItem)
+		protected.PUT("/items/:id", warehouseStaff, h.UpdateItem)
+		protected.DELETE("/items/:id", warehouseStaff, h.DeleteItem)
+		protected.GET("/items/trash", warehouseStaff, h.GetDeletedItems)
+		protected.POST("/items/:id/restore", warehouseStaff, h.RestoreItem)
+		protected.DELETE("/items/:id/purge", adminOnly, h.PurgeItem)
+		protected.GET("/items/search", h.SearchItems)
+		protected.GET("/items/labels", h.GetItemLabelSheet)
+		protected.GET("/items/:id/label", h.GetItemLabel)
+		protected.GET("/items/export", h.ExportItems)
+		protected.POST("/items/import", warehouseStaff, h.ImportItems)
+		protected.POST("/items/move", canTransact, h.MoveItems)
+		protected.GET("/items/:id/moves", h.GetItemMoves)
+		protected.PUT("/items/:id/custodian", canTransact, h.AssignItemCustodian)
 
 		// Transactions
-		api.GET("/transactions", h.GetTransactions)
-		api.POST("/transactions", h.CreateTransaction)
-		api.GET("/transactions/:id", h.GetTransaction)
-		api.PUT("/transactions/:id", h.UpdateTransaction)
-		api.DELETE("/transactions/:id", h.DeleteTransaction)
+		protected.GET("/transactions", h.GetTransactions)
+		protected.GET("/transactions/export", h.ExportTransactions)
+		protected.POST("/transactions", canTransact, h.CreateTransaction)
+		protected.GET("/transactions/:id", h.GetTransaction)
+		protected.PUT("/transactions/:id", warehouseStaff, h.UpdateTransaction)
+		protected.POST("/transactions/:id/reverse", adminOnly, h.ReverseTransaction)
+		protected.POST("/transactions/:id/receipt", canTransact, h.ConfirmReceipt)
+		protected.POST("/transactions/:id/reject", canTransact, h.RejectReceipt)
+
+		// Loans
+		protected.GET("/loans", h.GetLoans)
+		protected.GET("/loans/overdue", h.GetOverdueLoans)
+
+		// Transfer batches
+		protected.GET("/transfer-batches", h.GetTransferBatches)
+		protected.POST("/transfer-batches", canTransact, h.CreateTransferBatch)
+		protected.GET("/transfer-batches/:id", h.GetTransferBatch)
+
+		// Item requests
+		protected.GET("/item-requests", h.GetItemRequests)
+		protected.POST("/item-requests", canTransact, h.CreateItemRequest)
+		protected.GET("/item-requests/:id", h.GetItemRequest)
+		protected.POST("/item-requests/:id/approve", warehouseStaff, h.ApproveItemRequest)
+		protected.POST("/item-requests/:id/reject", warehouseStaff, h.RejectItemRequest)
+		protected.POST("/item-requests/:id/cancel", canTransact, h.CancelItemRequest)
+		protected.POST("/item-requests/:id/fulfill", warehouseStaff, h.FulfillItemRequest)
+
+		// Handover documents (BAST)
+		protected.GET("/handover-documents", h.GetHandoverDocuments)
+		protected.POST("/handover-documents", canTransact, h.CreateHandoverDocument)
+		protected.GET("/handover-documents/:id", h.GetHandoverDocument)
+		protected.GET("/handover-documents/:id/pdf", h.GetHandoverDocumentPDF)
+
+		// Maintenance
+		protected.GET("/maintenance-tickets", h.GetMaintenanceTickets)
+		protected.POST("/maintenance-tickets", canTransact, h.CreateMaintenanceTicket)
+		protected.GET("/maintenance-tickets/:id", h.GetMaintenanceTicket)
+		protected.PUT("/maintenance-tickets/:id", warehouseStaff, h.UpdateMaintenanceTicket)
+		protected.POST("/maintenance-tickets/:id/close", warehouseStaff, h.CloseMaintenanceTicket)
+
+		// Disposals (penghapusan barang)
+		protected.GET("/disposals", canReview, h.GetDisposals)
+		protected.POST("/disposals", warehouseStaff, h.CreateDisposal)
+		protected.GET("/disposals/:id", canReview, h.GetDisposal)
+		protected.POST("/disposals/:id/approve", adminOnly, h.ApproveDisposal)
+		protected.POST("/disposals/:id/reject", adminOnly, h.RejectDisposal)
+		protected.POST("/disposals/:id/execute", adminOnly, h.ExecuteDisposal)
+
+		// Stock opname
+		protected.GET("/stock-opnames", h.GetStockOpnames)
+		protected.POST("/stock-opnames", warehouseStaff, h.OpenStockOpname)
+		protected.GET("/stock-opnames/:id", h.GetStockOpname)
+		protected.POST("/stock-opnames/:id/scans", canTransact, h.ScanStockOpnameItem)
+		protected.POST("/stock-opnames/:id/close", warehouseStaff, h.CloseStockOpname)
+		protected.GET("/stock-opnames/:id/report", h.GetStockOpnameReport)
+		protected.POST("/stock-opnames/:id/corrections", warehouseStaff, h.PostStockOpnameCorrections)
+
+		// Storage locations
+		protected.GET("/storage-locations", h.GetStorageLocationTree)
+		protected.POST("/storage-locations", canTransact, h.CreateStorageLocation)
+		protected.GET("/storage-locations/:id", h.GetStorageLocation)
+		protected.PUT("/storage-locations/:id", canTransact, h.UpdateStorageLocation)
+		protected.DELETE("/storage-locations/:id", canTransact, h.DeleteStorageLocation)
+		protected.GET("/storage-locations/:id/items", h.GetStorageLocationItems)
+
+		// Warehouses
+		protected.GET("/warehouses", h.GetWarehouses)
+		protected.GET("/warehouses/:id", h.GetWarehouse)
+		protected.POST("/warehouses", adminOnly, h.CreateWarehouse)
+		protected.PUT("/warehouses/:id", adminOnly, h.UpdateWarehouse)
+		protected.DELETE("/warehouses/:id", adminOnly, h.DeleteWarehouse)
 
 		// OPDs
-		api.GET("/opds", h.GetOPDs)
-		api.POST("/opds", h.CreateOPD)
-		api.PUT("/opds/:id", h.UpdateOPD)
-		api.DELETE("/opds/:id", h.DeleteOPD)
+		protected.GET("/opds", h.GetOPDs)
+		protected.POST("/opds", adminOnly, h.CreateOPD)
+		protected.PUT("/opds/:id", adminOnly, h.UpdateOPD)
+		protected.PUT("/opds/:id/parent", adminOnly, h.MoveOPD)
+		protected.DELETE("/opds/:id", adminOnly, h.DeleteOPD)
+		protected.GET("/opds/trash", adminOnly, h.GetDeletedOPDs)
+		protected.POST("/opds/:id/restore", adminOnly, h.RestoreOPD)
+		protected.DELETE("/opds/:id/purge", adminOnly, h.PurgeOPD)
+		protected.GET("/opds/:id/lineage", h.GetOPDLineage)
+		protected.POST("/opds/merge", adminOnly, h.MergeOPDs)
+		protected.POST("/opds/split", adminOnly, h.SplitOPD)
+		protected.GET("/opd-reorganizations", h.GetReorganizations)
+		protected.GET("/opd-reorganizations/:id", h.GetReorganization)
+
+		// Custodians (penanggung jawab)
+		protected.GET("/custodians", h.GetCustodians)
+		protected.GET("/custodians/:id", h.GetCustodian)
+		protected.POST("/custodians", adminOnly, h.CreateCustodian)
+		protected.PUT("/custodians/:id", adminOnly, h.UpdateCustodian)
+		protected.DELETE("/custodians/:id", adminOnly, h.DeleteCustodian)
+		protected.GET("/custodians/:id/items", h.GetCustodianItems)
+		protected.GET("/custodians/:id/statement", h.GetCustodianStatementPDF)
 
 		// Categories
-		api.GET("/categories", h.GetCategories)
-		api.POST("/categories", h.CreateCategory)
-		api.PUT("/categories/:id", h.UpdateCategory)
-		api.DELETE("/categories/:id", h.DeleteCategory)
+		protected.GET("/categories", h.GetCategories)
+		protected.POST("/categories", adminOnly, h.CreateCategory)
+		protected.PUT("/categories/:id", adminOnly, h.UpdateCategory)
+		protected.DELETE("/categories/:id", adminOnly, h.DeleteCategory)
+		protected.GET("/categories/trash", adminOnly, h.GetDeletedCategories)
+		protected.POST("/categories/:id/restore", adminOnly, h.RestoreCategory)
+		protected.DELETE("/categories/:id/purge", adminOnly, h.PurgeCategory)
+
+		// Audit log
+		protected.GET("/audit", canAudit, h.GetAuditLogs)
 
 		// Dashboard
-		api.GET("/dashboard/summary", h.GetDashboardSummary)
-		api.GET("/dashboard/recent-transactions", h.GetRecentTransactions)
+		protected.GET("/dashboard/summary", h.GetDashboardSummary)
+		protected.GET("/dashboard/recent-transactions", h.GetRecentTransactions)
 	}
 
 	port := cfg.Port
@@ -106,4 +247,21 @@ func main() {
 
 	log.Printf("Server starting on port %s", port)
 	log.Fatal(r.Run(":" + port))
-}
\ No newline at end of file
+}
+
+// receiptExpiryInterval is how often overdue receipts are checked
+const receiptExpiryInterval = 10 * time.Minute
+
+func expireReceipts(transactions *services.TransactionService) {
+	ticker := time.NewTicker(receiptExpiryInterval)
+	defer ticker.Stop()
+	for {
+		expired, err := transactions.ExpireReceipts(time.Now())
+		if err != nil {
+			log.Println("Failed to expire receipts:", err)
+		} else if expired > 0 {
+			log.Printf("Expired %d overdue receipts", expired)
+		}
+		<-ticker.C
+	}
+}
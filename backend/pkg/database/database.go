@@ -1,8 +1,12 @@
 package database
 
 import (
+	"errors"
+
 	"warehouse-system/internal/models"
 
+	"github.com/google/uuid"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -20,10 +24,95 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 }
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
+		&models.Warehouse{},
 		&models.OPD{},
 		&models.Category{},
+		&models.Custodian{},
 		&models.Item{},
+		&models.ItemRequest{},
+		&models.ItemRequestLine{},
+		&models.ItemRequestEvent{},
+		&models.TransferBatch{},
 		&models.Transaction{},
-	)
+		&models.TransactionEdit{},
+		&models.User{},
+		&models.UserSession{},
+		&models.AuditLog{},
+		&models.DocumentSequence{},
+		&models.HandoverDocument{},
+		&models.HandoverDocumentLine{},
+		&models.StockOpname{},
+		&models.StockOpnameEntry{},
+		&models.MaintenanceTicket{},
+		&models.Disposal{},
+		&models.DisposalItem{},
+		&models.DisposalApproval{},
+		&models.StorageLocation{},
+		&models.ItemMove{},
+		&models.OPDReorganization{},
+		&models.OPDLineage{},
+	); err != nil {
+		return err
+	}
+
+	// OPD and category names used to be unique across deleted rows too.
+	// Rows deactivated before the trash existed get a deletion time, and
+	// rows soft-deleted through gorm's deleted_at go to the trash.
+	statements := []string{
+		"DROP INDEX IF EXISTS idx_opds_name",
+		"DROP INDEX IF EXISTS idx_categories_name",
+		"UPDATE items SET is_active = false WHERE is_active = true AND deleted_at IS NOT NULL",
+		"UPDATE opds SET is_active = false WHERE is_active = true AND deleted_at IS NOT NULL",
+		"UPDATE categories SET is_active = false WHERE is_active = true AND deleted_at IS NOT NULL",
+		"UPDATE items SET deleted_at = updated_at WHERE is_active = false AND deleted_at IS NULL",
+		"UPDATE opds SET deleted_at = updated_at WHERE is_active = false AND deleted_at IS NULL",
+		"UPDATE categories SET deleted_at = updated_at WHERE is_active = false AND deleted_at IS NULL",
+		// OPDs recorded before the hierarchy are top-level units
+		"UPDATE opds SET path = '/' || id || '/' WHERE path = ''",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return migrateWarehouses(db)
+}
+
+// migrateWarehouses creates the default warehouse and assigns it everything
+// recorded while there was a single, unnamed one
+func migrateWarehouses(db *gorm.DB) error {
+	var warehouse models.Warehouse
+	err := db.First(&warehouse, "is_default = ? AND is_active = ?", true, true).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		warehouse = models.Warehouse{
+			BaseModel:  models.BaseModel{ID: uuid.New()},
+			Name:       models.DefaultWarehouseName,
+			IsDefault:  true,
+			SoftDelete: models.Active(),
+		}
+		err = db.Create(&warehouse).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	statements := []string{
+		"UPDATE items SET current_warehouse_id = ? WHERE current_location = 'Gudang' AND current_warehouse_id IS NULL",
+		"UPDATE transactions SET source_warehouse_id = ? WHERE direction = 'Gudang → OPD' AND source_warehouse_id IS NULL",
+		"UPDATE transactions SET target_warehouse_id = ? WHERE direction = 'OPD → Gudang' AND target_warehouse_id IS NULL",
+		"UPDATE transfer_batches SET source_warehouse_id = ? WHERE direction = 'Gudang → OPD' AND source_warehouse_id IS NULL",
+		"UPDATE transfer_batches SET target_warehouse_id = ? WHERE direction = 'OPD → Gudang' AND target_warehouse_id IS NULL",
+		"UPDATE handover_documents SET source_warehouse_id = ? WHERE direction = 'Gudang → OPD' AND source_warehouse_id IS NULL",
+		"UPDATE handover_documents SET target_warehouse_id = ? WHERE direction = 'OPD → Gudang' AND target_warehouse_id IS NULL",
+		"UPDATE storage_locations SET warehouse_id = ? WHERE opd_id IS NULL AND warehouse_id IS NULL",
+		"UPDATE item_moves SET warehouse_id = ? WHERE opd_id IS NULL AND warehouse_id IS NULL",
+		"UPDATE stock_opnames SET warehouse_id = ? WHERE location = 'Gudang' AND warehouse_id IS NULL",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement, warehouse.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
\ No newline at end of file
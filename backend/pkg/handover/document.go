@@ -0,0 +1,201 @@
+package handover
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Document is the content of one BAST
+type Document struct {
+	Number   string
+	Date     time.Time
+	Source   string
+	Target   string
+	Location string
+	Officer  string
+	Lines    []Line
+}
+
+// Line is one handed over item
+type Line struct {
+	SerialNumber string
+	Name         string
+	Category     string
+	Condition    string
+}
+
+const (
+	pageMargin     = 20.0
+	bodyWidth      = 170.0
+	bodyFontSize   = 10.5
+	lineHeight     = 5.5
+	rowHeight      = 6.5
+	signatureSpace = 22.0
+)
+
+var tableColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"No", 10, "C"},
+	{"Nomor Seri", 40, "L"},
+	{"Nama Barang", 55, "L"},
+	{"Kategori", 35, "L"},
+	{"Kondisi", 30, "L"},
+}
+
+// Write renders doc as an A4 PDF
+func Write(w io.Writer, tmpl Template, doc Document) error {
+	data := TextData{
+		Number:   doc.Number,
+		Day:      indonesianDays[doc.Date.Weekday()],
+		Date:     FormatDate(doc.Date),
+		Source:   doc.Source,
+		Target:   doc.Target,
+		Location: doc.Location,
+		Count:    len(doc.Lines),
+	}
+	opening, err := tmpl.execute("opening", tmpl.Opening, data)
+	if err != nil {
+		return err
+	}
+	closing, err := tmpl.execute("closing", tmpl.Closing, data)
+	if err != nil {
+		return err
+	}
+
+	pdf, tr := newPage()
+	writeLetterhead(pdf, tr, tmpl.HeaderLines)
+
+	pdf.SetFont("Helvetica", "BU", 12)
+	pdf.CellFormat(bodyWidth, 6, tr(tmpl.Title), "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	pdf.CellFormat(bodyWidth, 6, tr("Nomor: "+doc.Number), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.MultiCell(bodyWidth, lineHeight, tr(opening), "", "J", false)
+	pdf.Ln(2)
+
+	writeTable(pdf, tr, doc.Lines)
+	pdf.Ln(3)
+
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	if doc.Location != "" {
+		pdf.MultiCell(bodyWidth, lineHeight, tr("Lokasi penempatan: "+doc.Location), "", "L", false)
+		pdf.Ln(2)
+	}
+	pdf.MultiCell(bodyWidth, lineHeight, tr(closing), "", "J", false)
+	pdf.Ln(6)
+
+	writeSignatures(pdf, tr, tmpl, doc)
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// newPage starts an A4 PDF with its first page
+func newPage() (*gofpdf.Fpdf, func(string) string) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+	return pdf, tr
+}
+
+func writeLetterhead(pdf *gofpdf.Fpdf, tr func(string) string, lines []string) {
+	pdf.SetFont("Helvetica", "B", 12)
+	for _, line := range lines {
+		pdf.CellFormat(bodyWidth, 6, tr(line), "", 1, "C", false, 0, "")
+	}
+	if len(lines) > 0 {
+		y := pdf.GetY() + 2
+		pdf.SetLineWidth(0.6)
+		pdf.Line(pageMargin, y, pageMargin+bodyWidth, y)
+		pdf.SetLineWidth(0.2)
+		pdf.Ln(6)
+	}
+}
+
+func writeTable(pdf *gofpdf.Fpdf, tr func(string) string, lines []Line) {
+	_, pageHeight := pdf.GetPageSize()
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9.5)
+		pdf.SetFillColor(230, 230, 230)
+		for _, col := range tableColumns {
+			pdf.CellFormat(col.width, rowHeight, col.title, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9.5)
+	}
+
+	header()
+	for i, line := range lines {
+		// Break pages manually so the header repeats on every page
+		if pdf.GetY()+rowHeight > pageHeight-pageMargin {
+			pdf.AddPage()
+			header()
+		}
+		values := []string{strconv.Itoa(i + 1), line.SerialNumber, line.Name, line.Category, line.Condition}
+		for j, col := range tableColumns {
+			text := fitText(pdf, tr(values[j]), col.width-2)
+			pdf.CellFormat(col.width, rowHeight, text, "1", 0, col.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func writeSignatures(pdf *gofpdf.Fpdf, tr func(string) string, tmpl Template, doc Document) {
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+signatureSpace+30 > pageHeight-pageMargin {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	pdf.CellFormat(bodyWidth, lineHeight, tr(tmpl.City+", "+FormatDate(doc.Date)), "", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	blocks := []struct {
+		title, organization, name string
+	}{
+		{tmpl.GiverTitle, doc.Source, ""},
+		{tmpl.ReceiverTitle, doc.Target, ""},
+		{tmpl.OfficerTitle, "", doc.Officer},
+	}
+	width := bodyWidth / float64(len(blocks))
+	top := pdf.GetY()
+	for i, block := range blocks {
+		x := pageMargin + float64(i)*width
+		pdf.SetXY(x, top)
+		pdf.SetFont("Helvetica", "B", bodyFontSize)
+		pdf.CellFormat(width, lineHeight, tr(block.title), "", 2, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", bodyFontSize)
+		pdf.CellFormat(width, lineHeight, fitText(pdf, tr(block.organization), width-2), "", 2, "C", false, 0, "")
+
+		pdf.SetXY(x, top+2*lineHeight+signatureSpace)
+		name := "(................................)"
+		if block.name != "" {
+			name = "( " + block.name + " )"
+		}
+		pdf.CellFormat(width, lineHeight, fitText(pdf, tr(name), width-2), "", 0, "C", false, 0, "")
+	}
+	pdf.SetXY(pageMargin, top+3*lineHeight+signatureSpace)
+}
+
+// fitText trims text until it fits in width at the current font
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
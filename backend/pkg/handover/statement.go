@@ -0,0 +1,122 @@
+package handover
+
+import (
+	"io"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Statement is the content of a custodian's asset statement: the items the
+// custodian is responsible for on Date
+type Statement struct {
+	Date     time.Time
+	Name     string
+	NIP      string
+	Position string
+	OPD      string
+	Lines    []Line
+}
+
+// identityLabelWidth is the width of the labels in the custodian's details
+const identityLabelWidth = 35.0
+
+// WriteStatement renders statement as an A4 PDF
+func WriteStatement(w io.Writer, tmpl Template, statement Statement) error {
+	data := StatementData{
+		Name:     statement.Name,
+		NIP:      statement.NIP,
+		Position: statement.Position,
+		OPD:      statement.OPD,
+		Date:     FormatDate(statement.Date),
+		Count:    len(statement.Lines),
+	}
+	opening, err := tmpl.execute("statement_opening", tmpl.StatementOpening, data)
+	if err != nil {
+		return err
+	}
+	closing, err := tmpl.execute("statement_closing", tmpl.StatementClosing, data)
+	if err != nil {
+		return err
+	}
+
+	pdf, tr := newPage()
+	writeLetterhead(pdf, tr, tmpl.HeaderLines)
+
+	pdf.SetFont("Helvetica", "BU", 12)
+	pdf.MultiCell(bodyWidth, 6, tr(tmpl.StatementTitle), "", "C", false)
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	identity := []struct{ label, value string }{
+		{"Nama", statement.Name},
+		{"NIP", statement.NIP},
+		{"Jabatan", statement.Position},
+		{"Unit Kerja", statement.OPD},
+	}
+	for _, row := range identity {
+		pdf.CellFormat(identityLabelWidth, lineHeight, tr(row.label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, lineHeight, ":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(bodyWidth-identityLabelWidth-5, lineHeight, tr(row.value), "", "L", false)
+	}
+	pdf.Ln(2)
+
+	pdf.MultiCell(bodyWidth, lineHeight, tr(opening), "", "J", false)
+	pdf.Ln(2)
+
+	writeTable(pdf, tr, statement.Lines)
+	pdf.Ln(3)
+
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	pdf.MultiCell(bodyWidth, lineHeight, tr(closing), "", "J", false)
+	pdf.Ln(6)
+
+	writeStatementSignatures(pdf, tr, tmpl, statement)
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// writeStatementSignatures puts the head of the OPD on the left and the
+// custodian, named with their NIP, on the right
+func writeStatementSignatures(pdf *gofpdf.Fpdf, tr func(string) string, tmpl Template, statement Statement) {
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+signatureSpace+30 > pageHeight-pageMargin {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("Helvetica", "", bodyFontSize)
+	pdf.CellFormat(bodyWidth, lineHeight, tr(tmpl.City+", "+FormatDate(statement.Date)), "", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	blocks := []struct {
+		title, name, nip string
+	}{
+		{tmpl.SupervisorTitle, "", ""},
+		{tmpl.CustodianTitle, statement.Name, statement.NIP},
+	}
+	width := bodyWidth / float64(len(blocks))
+	top := pdf.GetY()
+	for i, block := range blocks {
+		x := pageMargin + float64(i)*width
+		pdf.SetXY(x, top)
+		pdf.SetFont("Helvetica", "B", bodyFontSize)
+		pdf.CellFormat(width, lineHeight, fitText(pdf, tr(block.title), width-2), "", 2, "C", false, 0, "")
+
+		pdf.SetXY(x, top+lineHeight+signatureSpace)
+		pdf.SetFont("Helvetica", "", bodyFontSize)
+		name := "(................................)"
+		if block.name != "" {
+			name = "( " + block.name + " )"
+		}
+		pdf.CellFormat(width, lineHeight, fitText(pdf, tr(name), width-2), "", 2, "C", false, 0, "")
+		nip := "NIP. ................................"
+		if block.nip != "" {
+			nip = "NIP. " + block.nip
+		}
+		pdf.CellFormat(width, lineHeight, fitText(pdf, tr(nip), width-2), "", 0, "C", false, 0, "")
+	}
+	pdf.SetXY(pageMargin, top+3*lineHeight+signatureSpace)
+}
@@ -0,0 +1,198 @@
+package label
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Layout of a single label in pixels. PNG output is drawn at this size and
+// then scaled up for print resolution; SVG uses it as its view box.
+const (
+	labelWidth   = 320
+	labelHeight  = 140
+	labelMargin  = 10
+	qrSize       = 120
+	barHeight    = 60
+	lineHeight   = 16
+	charWidth    = 7
+	pngUpscale   = 3
+	captionIndex = 0
+)
+
+// layout computes where the symbol and text lines go
+type layout struct {
+	width, height  int
+	symbolX        int
+	symbolY        int
+	moduleW        int
+	moduleH        int
+	textX          int
+	textY          int
+	maxTextColumns int
+}
+
+func newLayout(sym symbol) layout {
+	if sym.linear() {
+		moduleW := (labelWidth - 2*labelMargin) / sym.width()
+		if moduleW < 1 {
+			moduleW = 1
+		}
+		width := labelWidth
+		if needed := sym.width()*moduleW + 2*labelMargin; needed > width {
+			width = needed
+		}
+		return layout{
+			width:          width,
+			height:         labelHeight,
+			symbolX:        (width - sym.width()*moduleW) / 2,
+			symbolY:        labelMargin,
+			moduleW:        moduleW,
+			moduleH:        barHeight,
+			textX:          labelMargin,
+			textY:          labelMargin + barHeight + lineHeight,
+			maxTextColumns: (width - 2*labelMargin) / charWidth,
+		}
+	}
+
+	module := qrSize / sym.width()
+	if module < 1 {
+		module = 1
+	}
+	offset := (qrSize - sym.width()*module) / 2
+	textX := labelMargin + qrSize + labelMargin
+	return layout{
+		width:          labelWidth,
+		height:         labelHeight,
+		symbolX:        labelMargin + offset,
+		symbolY:        labelMargin + offset,
+		moduleW:        module,
+		moduleH:        module,
+		textX:          textX,
+		textY:          labelMargin + lineHeight,
+		maxTextColumns: (labelWidth - textX - labelMargin) / charWidth,
+	}
+}
+
+func (l Label) textLines() []string {
+	return append([]string{l.Caption}, l.Lines...)
+}
+
+// WritePNG renders a label as a PNG image
+func WritePNG(w io.Writer, lbl Label, symbology Symbology) error {
+	sym, err := encode(lbl.Content, symbology)
+	if err != nil {
+		return err
+	}
+	lay := newLayout(sym)
+
+	img := image.NewGray(image.Rect(0, 0, lay.width, lay.height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for y, row := range sym.modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			rect := image.Rect(
+				lay.symbolX+x*lay.moduleW, lay.symbolY+y*lay.moduleH,
+				lay.symbolX+(x+1)*lay.moduleW, lay.symbolY+(y+1)*lay.moduleH,
+			)
+			draw.Draw(img, rect, image.Black, image.Point{}, draw.Src)
+		}
+	}
+
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(color.Black), Face: basicfont.Face7x13}
+	for i, line := range lbl.textLines() {
+		y := lay.textY + i*lineHeight
+		if y > lay.height-labelMargin/2 {
+			break
+		}
+		drawer.Dot = fixed.P(lay.textX, y)
+		drawer.DrawString(truncate(line, lay.maxTextColumns))
+	}
+
+	return png.Encode(w, upscale(img, pngUpscale))
+}
+
+// upscale enlarges an image by an integer factor without smoothing, which
+// keeps symbol modules sharp for scanners
+func upscale(src *image.Gray, factor int) *image.Gray {
+	b := src.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, b.Dx()*factor, b.Dy()*factor))
+	for y := 0; y < dst.Bounds().Dy(); y++ {
+		for x := 0; x < dst.Bounds().Dx(); x++ {
+			dst.SetGray(x, y, src.GrayAt(x/factor, y/factor))
+		}
+	}
+	return dst
+}
+
+// WriteSVG renders a label as a scalable SVG document
+func WriteSVG(w io.Writer, lbl Label, symbology Symbology) error {
+	sym, err := encode(lbl.Content, symbology)
+	if err != nil {
+		return err
+	}
+	lay := newLayout(sym)
+
+	ew := &errWriter{w: w}
+	ew.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		lay.width*pngUpscale, lay.height*pngUpscale, lay.width, lay.height)
+	ew.printf(`<rect width="%d" height="%d" fill="#fff"/>`, lay.width, lay.height)
+	ew.printf(`<g fill="#000" shape-rendering="crispEdges">`)
+	for y, row := range sym.modules {
+		// Merge horizontal runs of dark modules into one rectangle each
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			ew.printf(`<rect x="%d" y="%d" width="%d" height="%d"/>`,
+				lay.symbolX+start*lay.moduleW, lay.symbolY+y*lay.moduleH,
+				(x-start)*lay.moduleW, lay.moduleH)
+		}
+	}
+	ew.printf(`</g>`)
+	ew.printf(`<g font-family="monospace" font-size="12" fill="#000">`)
+	for i, line := range lbl.textLines() {
+		y := lay.textY + i*lineHeight
+		if y > lay.height-labelMargin/2 {
+			break
+		}
+		weight := ""
+		if i == captionIndex {
+			weight = ` font-weight="bold"`
+		}
+		ew.printf(`<text x="%d" y="%d"%s>%s</text>`, lay.textX, y, weight,
+			html.EscapeString(truncate(line, lay.maxTextColumns)))
+	}
+	ew.printf(`</g></svg>`)
+	return ew.err
+}
+
+// errWriter keeps the first write error so that a sequence of writes can be
+// checked once at the end
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
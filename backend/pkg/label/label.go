@@ -0,0 +1,107 @@
+// Package label renders printable asset labels carrying a QR code or a
+// Code 128 barcode next to a few lines of text.
+package label
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+)
+
+type Symbology string
+
+const (
+	SymbologyQR      Symbology = "qr"
+	SymbologyCode128 Symbology = "code128"
+)
+
+var (
+	ErrUnsupportedSymbology = errors.New("unsupported symbology, expected qr or code128")
+	ErrUnencodable          = errors.New("content cannot be encoded with this symbology")
+)
+
+func ParseSymbology(name string) (Symbology, error) {
+	switch Symbology(strings.ToLower(name)) {
+	case SymbologyQR:
+		return SymbologyQR, nil
+	case SymbologyCode128:
+		return SymbologyCode128, nil
+	}
+	return "", ErrUnsupportedSymbology
+}
+
+// Label is the content of one label. Content is what the symbol encodes,
+// Caption is printed as the human-readable code and Lines follow below it.
+type Label struct {
+	Content string
+	Caption string
+	Lines   []string
+}
+
+// symbol is an encoded QR or barcode as a grid of dark modules. A Code 128
+// barcode has a single row that is stretched vertically when drawn.
+type symbol struct {
+	modules [][]bool
+}
+
+func (s symbol) width() int  { return len(s.modules[0]) }
+func (s symbol) height() int { return len(s.modules) }
+func (s symbol) linear() bool {
+	return len(s.modules) == 1
+}
+
+func encode(content string, symbology Symbology) (symbol, error) {
+	var code barcode.Barcode
+	var err error
+	switch symbology {
+	case SymbologyQR:
+		code, err = qr.Encode(content, qr.M, qr.Auto)
+	case SymbologyCode128:
+		code, err = code128.Encode(content)
+	default:
+		err = ErrUnsupportedSymbology
+	}
+	if err == ErrUnsupportedSymbology {
+		return symbol{}, err
+	}
+	if err != nil {
+		return symbol{}, fmt.Errorf("%w: %v", ErrUnencodable, err)
+	}
+
+	bounds := code.Bounds()
+	rows := bounds.Dy()
+	if symbology == SymbologyCode128 {
+		rows = 1
+	}
+
+	modules := make([][]bool, rows)
+	for y := 0; y < rows; y++ {
+		modules[y] = make([]bool, bounds.Dx())
+		for x := 0; x < bounds.Dx(); x++ {
+			modules[y][x] = isDark(code.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return symbol{modules: modules}, nil
+}
+
+func isDark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return (r+g+b)/3 < 0x8000
+}
+
+// truncate shortens text to at most n characters
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-3]) + "..."
+}
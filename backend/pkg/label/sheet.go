@@ -0,0 +1,116 @@
+package label
+
+import (
+	"io"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// A4 sheet of 3 x 8 labels, matching the common 70 x 37 mm sticker stock
+const (
+	sheetColumns    = 3
+	sheetRows       = 8
+	sheetCellWidth  = 70.0
+	sheetCellHeight = 37.0
+	sheetTopMargin  = 0.5
+	sheetPadding    = 3.0
+	sheetFontSize   = 7.5
+	sheetLineHeight = 3.4
+)
+
+// SheetCapacity is the number of labels that fit on one page
+const SheetCapacity = sheetColumns * sheetRows
+
+// WritePDFSheet renders labels onto as many A4 pages as needed. Symbols are
+// drawn as vector rectangles so they stay sharp at any printer resolution.
+func WritePDFSheet(w io.Writer, labels []Label, symbology Symbology) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", sheetFontSize)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	if len(labels) == 0 {
+		pdf.AddPage()
+	}
+	for i, lbl := range labels {
+		if i%SheetCapacity == 0 {
+			pdf.AddPage()
+		}
+		slot := i % SheetCapacity
+		x := float64(slot%sheetColumns) * sheetCellWidth
+		y := sheetTopMargin + float64(slot/sheetColumns)*sheetCellHeight
+
+		sym, err := encode(lbl.Content, symbology)
+		if err != nil {
+			return err
+		}
+		drawSheetLabel(pdf, tr, sym, lbl, x, y)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+func drawSheetLabel(pdf *gofpdf.Fpdf, tr func(string) string, sym symbol, lbl Label, x, y float64) {
+	inner := sheetCellHeight - 2*sheetPadding
+	textX := x + sheetPadding
+	textY := y + sheetPadding
+	textWidth := sheetCellWidth - 2*sheetPadding
+
+	pdf.SetFillColor(0, 0, 0)
+	if sym.linear() {
+		barHeight := inner * 0.45
+		module := textWidth / float64(sym.width())
+		drawModules(pdf, sym, x+sheetPadding, y+sheetPadding, module, barHeight)
+		textY += barHeight + 1
+	} else {
+		module := inner / float64(sym.width())
+		drawModules(pdf, sym, x+sheetPadding, y+sheetPadding, module, module)
+		textX += inner + sheetPadding
+		textWidth -= inner + sheetPadding
+	}
+
+	for i, line := range lbl.textLines() {
+		if textY+sheetLineHeight > y+sheetCellHeight-sheetPadding {
+			break
+		}
+		style := ""
+		if i == captionIndex {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, sheetFontSize)
+		pdf.SetXY(textX, textY)
+		pdf.CellFormat(textWidth, sheetLineHeight, fitText(pdf, tr(line), textWidth), "", 0, "L", false, 0, "")
+		textY += sheetLineHeight
+	}
+}
+
+func drawModules(pdf *gofpdf.Fpdf, sym symbol, x, y, moduleW, moduleH float64) {
+	for row, modules := range sym.modules {
+		for col := 0; col < len(modules); {
+			if !modules[col] {
+				col++
+				continue
+			}
+			start := col
+			for col < len(modules) && modules[col] {
+				col++
+			}
+			pdf.Rect(x+float64(start)*moduleW, y+float64(row)*moduleH, float64(col-start)*moduleW, moduleH, "F")
+		}
+	}
+}
+
+// fitText trims text until it fits in width at the current font
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
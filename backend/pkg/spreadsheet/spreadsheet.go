@@ -0,0 +1,96 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format, expected csv or xlsx")
+
+// ParseFormat accepts a format name such as "csv" or "xlsx"
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimPrefix(name, "."))) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// FormatFromFilename detects the format from a file extension
+func FormatFromFilename(filename string) (Format, error) {
+	return ParseFormat(filepath.Ext(filename))
+}
+
+// ReadAll reads every row of a CSV file or of one sheet of an XLSX workbook.
+// An empty sheet name selects the first sheet.
+func ReadAll(r io.Reader, format Format, sheet string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatXLSX:
+		return readXLSX(r, sheet)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+
+	// Drop the byte order mark Excel writes in front of UTF-8 CSV files
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	// Spreadsheets set to an Indonesian locale export with semicolons
+	if first, err := br.Peek(br.Buffered()); err == nil {
+		line, _, _ := bytes.Cut(first, []byte("\n"))
+		if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+			reader.Comma = ';'
+		}
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	return rows, nil
+}
+
+func readXLSX(r io.Reader, sheet string) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open xlsx: %w", err)
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("read sheet %q: %w", sheet, err)
+	}
+	return rows, nil
+}
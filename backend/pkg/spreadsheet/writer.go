@@ -0,0 +1,145 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Writer writes rows one at a time so that large exports never have to be
+// held in memory. Close must be called to finish the file.
+type Writer interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// formulaPrefixes are the characters that make spreadsheet programs read a
+// cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula prefixes a value that would be read as a formula with an
+// apostrophe, so free text such as a note starting with "=" stays text when
+// a CSV file is opened. XLSX cells are typed as text and need no escaping.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format Format) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter returns a streaming writer for the format. sheet names the
+// worksheet of an XLSX file and is ignored for CSV.
+func NewWriter(w io.Writer, format Format, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	rows   int
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	return &csvWriter{writer: csv.NewWriter(&bomWriter{out: w})}, nil
+}
+
+// bomWriter puts the byte order mark, which makes Excel open the file as
+// UTF-8, in front of the first bytes written. Nothing reaches the output
+// until the first rows are flushed, so an export failing before that can
+// still report an error.
+type bomWriter struct {
+	out     io.Writer
+	started bool
+}
+
+func (w *bomWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		if _, err := w.out.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return 0, err
+		}
+	}
+	return w.out.Write(p)
+}
+
+func (w *csvWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeFormula(value)
+	}
+	if err := w.writer.Write(escaped); err != nil {
+		return err
+	}
+	w.rows++
+	if w.rows%500 == 0 {
+		w.writer.Flush()
+		return w.writer.Error()
+	}
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, sheet string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if sheet != "" {
+		if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	// The stream writer spills rows to a temporary file instead of memory
+	stream, err := file.NewStreamWriter(file.GetSheetName(0))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: file, stream: stream}, nil
+}
+
+func (w *xlsxWriter) WriteRow(values []string) error {
+	w.row++
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, cells)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}